@@ -0,0 +1,113 @@
+package bmfont
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// AnimationFrame is one atlas rect of an animated char
+type AnimationFrame struct {
+	X        uint16 `json:"x"`
+	Y        uint16 `json:"y"`
+	Width    uint16 `json:"width"`
+	Height   uint16 `json:"height"`
+	Page     uint8  `json:"page"`
+	Duration uint16 `json:"duration"` // Milliseconds
+}
+
+func (af *AnimationFrame) fromBinary(b []byte) {
+	af.X = binary.LittleEndian.Uint16(b[0:2])
+	af.Y = binary.LittleEndian.Uint16(b[2:4])
+	af.Width = binary.LittleEndian.Uint16(b[4:6])
+	af.Height = binary.LittleEndian.Uint16(b[6:8])
+	af.Page = b[8]
+	af.Duration = binary.LittleEndian.Uint16(b[9:11])
+}
+
+func (af *AnimationFrame) toBinary(b []byte) {
+	binary.LittleEndian.PutUint16(b[0:2], af.X)
+	binary.LittleEndian.PutUint16(b[2:4], af.Y)
+	binary.LittleEndian.PutUint16(b[4:6], af.Width)
+	binary.LittleEndian.PutUint16(b[6:8], af.Height)
+	b[8] = af.Page
+	binary.LittleEndian.PutUint16(b[9:11], af.Duration)
+}
+
+// Animations block layout: for every animated char its id (uint32), frame
+// count (uint16) and then the frames, 11 bytes each
+func animationsFromBinary(b []byte) (map[uint32][]AnimationFrame, error) {
+	animations := make(map[uint32][]AnimationFrame)
+	for len(b) != 0 {
+		if len(b) < 6 {
+			return nil, fmt.Errorf("Truncated animation header")
+		}
+		id := binary.LittleEndian.Uint32(b[0:4])
+		framesCnt := int(binary.LittleEndian.Uint16(b[4:6]))
+		b = b[6:]
+
+		if len(b) < framesCnt*11 {
+			return nil, fmt.Errorf("Truncated frames of char %v", id)
+		}
+		frames := make([]AnimationFrame, framesCnt)
+		for i := range frames {
+			frames[i].fromBinary(b[i*11 : i*11+11])
+		}
+		animations[id] = frames
+		b = b[framesCnt*11:]
+	}
+	return animations, nil
+}
+
+func animationsToBinary(animations map[uint32][]AnimationFrame) []byte {
+	ids := make([]uint32, 0, len(animations))
+	for id := range animations {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var b []byte
+	for _, id := range ids {
+		frames := animations[id]
+		header := make([]byte, 6, 6+len(frames)*11)
+		binary.LittleEndian.PutUint32(header[0:4], id)
+		binary.LittleEndian.PutUint16(header[4:6], uint16(len(frames)))
+		framesData := make([]byte, len(frames)*11)
+		for i := range frames {
+			frames[i].toBinary(framesData[i*11 : i*11+11])
+		}
+		b = append(append(b, header...), framesData...)
+	}
+	return b
+}
+
+// FrameAt returns the frame of an animated char shown at time t since the
+// animation start. Animations loop.
+func (f *Font) FrameAt(id uint32, t time.Duration) (AnimationFrame, bool) {
+	frames := f.Animations[id]
+	if len(frames) == 0 {
+		return AnimationFrame{}, false
+	}
+
+	var total time.Duration
+	for _, frame := range frames {
+		total += time.Duration(frame.Duration) * time.Millisecond
+	}
+	if total == 0 {
+		return frames[0], true
+	}
+
+	t %= total
+	if t < 0 {
+		t += total
+	}
+	for _, frame := range frames {
+		d := time.Duration(frame.Duration) * time.Millisecond
+		if t < d {
+			return frame, true
+		}
+		t -= d
+	}
+	return frames[len(frames)-1], true
+}
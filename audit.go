@@ -0,0 +1,223 @@
+package bmfont
+
+import (
+	"fmt"
+	"image"
+	"math"
+)
+
+// Issue is a problem found by one of the font checks
+type Issue struct {
+	Char    *Char // Char the issue refers to, nil for font-wide issues
+	Message string
+}
+
+func (i Issue) String() string {
+	if i.Char == nil {
+		return i.Message
+	}
+	return fmt.Sprintf("char %v (%q): %v", i.Char.Id, rune(i.Char.Id), i.Message)
+}
+
+func newIssue(c *Char, format string, args ...interface{}) Issue {
+	return Issue{Char: c, Message: fmt.Sprintf(format, args...)}
+}
+
+// Value of the distance field at x, y in the 0-255 range. 128 is the glyph edge.
+func sdfValue(img image.Image, x, y int, chnl uint8, fieldType string) uint8 {
+	r, g, b, a := img.At(x, y).RGBA()
+	r, g, b, a = r>>8, g>>8, b>>8, a>>8
+
+	switch chnl {
+	case CHNL_BLUE:
+		return uint8(b)
+	case CHNL_GREEN:
+		return uint8(g)
+	case CHNL_RED:
+		return uint8(r)
+	case CHNL_ALPHA:
+		return uint8(a)
+	}
+
+	if fieldType == "msdf" || fieldType == "mtsdf" {
+		// median of three
+		return uint8(max(min(r, g), min(max(r, g), b)))
+	}
+	return uint8(r)
+}
+
+// Pixels between each side of the glyph rect and the first pixel inside the
+// glyph, in left, top, right, bottom order. ok is false for empty glyphs.
+func sdfSpread(img image.Image, c *Char, chnl uint8, fieldType string) (spread [4]int, ok bool) {
+	x0, y0 := img.Bounds().Min.X+int(c.X), img.Bounds().Min.Y+int(c.Y)
+	w, h := int(c.Width), int(c.Height)
+	inside := func(x, y int) bool {
+		return sdfValue(img, x0+x, y0+y, chnl, fieldType) >= 128
+	}
+
+	spread = [4]int{w, h, w, h}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if inside(x, y) {
+				ok = true
+				spread[0] = min(spread[0], x)
+				spread[1] = min(spread[1], y)
+				spread[2] = min(spread[2], w-1-x)
+				spread[3] = min(spread[3], h-1-y)
+			}
+		}
+	}
+	return spread, ok
+}
+
+// CheckSDFPadding reports distance field fonts whose declared distance range
+// does not fit into the glyph padding. Such fonts look fine at native size but
+// show clipped gradients once scaled. Glyph spread is measured on pages when
+// they are provided.
+func (f *Font) CheckSDFPadding(pages PageImages) []Issue {
+	if f.DistanceField == nil || f.DistanceField.DistanceRange <= 0 {
+		return nil
+	}
+	var issues []Issue
+
+	fieldType := f.DistanceField.FieldType
+	halfRange := float64(f.DistanceField.DistanceRange) / 2
+
+	if i := f.Info; i != nil {
+		padding := [4]uint8{i.PaddingLeft, i.PaddingUp, i.PaddingRight, i.PaddingDown}
+		for side, name := range []string{"left", "top", "right", "bottom"} {
+			if float64(padding[side]) < halfRange {
+				issues = append(issues, newIssue(nil, "%v padding %v is less than half of distance range %v",
+					name, padding[side], f.DistanceField.DistanceRange))
+			}
+		}
+	}
+
+	for ci := range f.Chars {
+		c := &f.Chars[ci]
+		if c.Width == 0 || c.Height == 0 || int(c.Page) >= len(pages) || pages[c.Page] == nil {
+			continue
+		}
+
+		spread, ok := sdfSpread(pages[c.Page], c, f.CharChnl(c), fieldType)
+		if !ok {
+			continue
+		}
+		for side, name := range []string{"left", "top", "right", "bottom"} {
+			// Half a pixel of tolerance for the edge rasterization
+			if float64(spread[side])+0.5 < halfRange {
+				issues = append(issues, newIssue(c, "%v spread is %vpx, distance range %v needs %vpx",
+					name, spread[side], f.DistanceField.DistanceRange, halfRange))
+			}
+		}
+	}
+
+	return issues
+}
+
+const DEFAULT_ADVANCE_SIDE_BEARING = 1
+
+type AdvanceAuditOptions struct {
+	// Allowed difference between Xadvance and the right edge of the bitmap,
+	// 0 means a quarter of the font size but at least 2 pixels
+	Tolerance int
+	// Rewrite flagged advances to Xoffset + Width + SideBearing
+	Fix         bool
+	SideBearing int16 // 0 means DEFAULT_ADVANCE_SIDE_BEARING
+}
+
+// AuditAdvances flags chars whose Xadvance does not fit their bitmap: the
+// advance ends far before the right edge of the bitmap (glyphs overlap) or
+// far after it (gaps). Empty chars such as spaces are not checked.
+func (f *Font) AuditAdvances(opts *AdvanceAuditOptions) []Issue {
+	if opts == nil {
+		opts = &AdvanceAuditOptions{}
+	}
+	tolerance := opts.Tolerance
+	if tolerance == 0 {
+		tolerance = max(2, f.referenceSize()/4)
+	}
+	sideBearing := opts.SideBearing
+	if sideBearing == 0 {
+		sideBearing = DEFAULT_ADVANCE_SIDE_BEARING
+	}
+
+	var issues []Issue
+	for i := range f.Chars {
+		c := &f.Chars[i]
+		if c.Width == 0 {
+			continue
+		}
+
+		right := int(c.Xoffset) + int(c.Width)
+		diff := int(c.Xadvance) - right
+		if diff >= -tolerance && diff <= tolerance {
+			continue
+		}
+
+		if opts.Fix {
+			fixed := int16(min(right+int(sideBearing), math.MaxInt16))
+			issues = append(issues, newIssue(c, "xadvance %v is %+d from bitmap edge %v, set to %v", c.Xadvance, diff, right, fixed))
+			fixedChar := *c
+			fixedChar.Xadvance = fixed
+			f.setCharAt(i, fixedChar)
+		} else {
+			issues = append(issues, newIssue(c, "xadvance %v is %+d from bitmap edge %v", c.Xadvance, diff, right))
+		}
+	}
+	return issues
+}
+
+// Accepted range of the space advance relative to the average advance of
+// lowercase letters and of digits
+const (
+	SPACE_ADVANCE_MIN_RATIO = 0.2
+	SPACE_ADVANCE_MAX_RATIO = 1.5
+)
+
+func (f *Font) averageAdvance(from, to rune) (float64, bool) {
+	total, count := 0, 0
+	for r := from; r <= to; r++ {
+		if c := f.FindChar(uint32(r)); c != nil {
+			total += int(c.Xadvance)
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, false
+	}
+	return float64(total) / float64(count), true
+}
+
+// AuditSpaceAdvance checks the space advance against the average advance of
+// lowercase letters and digits. Zero or tiny advances glue words together,
+// huge ones leave gaps, both are typical results of broken exports.
+func (f *Font) AuditSpaceAdvance() []Issue {
+	space := f.FindChar(' ')
+	if space == nil {
+		return []Issue{newIssue(nil, "font has no space char")}
+	}
+	if space.Xadvance <= 0 {
+		return []Issue{newIssue(space, "space advance %v glues words together", space.Xadvance)}
+	}
+
+	var issues []Issue
+	check := func(name string, reference float64) {
+		ratio := float64(space.Xadvance) / reference
+		if ratio < SPACE_ADVANCE_MIN_RATIO {
+			issues = append(issues, newIssue(space, "space advance %v is suspiciously small, average %v advance is %.1f",
+				space.Xadvance, name, reference))
+		} else if ratio > SPACE_ADVANCE_MAX_RATIO {
+			issues = append(issues, newIssue(space, "space advance %v is suspiciously large, average %v advance is %.1f",
+				space.Xadvance, name, reference))
+		}
+	}
+
+	if lowercase, ok := f.averageAdvance('a', 'z'); ok && lowercase > 0 {
+		check("lowercase", lowercase)
+	}
+	if digits, ok := f.averageAdvance('0', '9'); ok && digits > 0 {
+		check("digit", digits)
+	}
+	return issues
+}
@@ -0,0 +1,115 @@
+package bmfont
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"iter"
+)
+
+type BlockHeader struct {
+	Id     uint8  // Use BLOCK_TYPE_ consts
+	Length uint32 // Payload size in bytes, not including the header
+}
+
+// BlockReader walks the blocks of a binary descriptor without interpreting them
+type BlockReader struct {
+	r          io.Reader
+	version    uint8
+	headerRead bool
+	err        error
+}
+
+func NewBlockReader(r io.Reader) *BlockReader {
+	return &BlockReader{r: r}
+}
+
+// Version reads the file header if needed and returns the format version
+func (br *BlockReader) Version() (uint8, error) {
+	if !br.headerRead {
+		br.headerRead = true
+
+		var header [4]byte
+		if n, err := io.ReadFull(br.r, header[:]); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				br.err = fmt.Errorf("Buffer too short for header: %v bytes", n)
+			} else {
+				br.err = err
+			}
+		} else if header[0] != 'B' || header[1] != 'M' || header[2] != 'F' {
+			br.err = fmt.Errorf("Invalid identifier %v", header[:3])
+		} else {
+			br.version = header[3]
+		}
+	}
+	return br.version, br.err
+}
+
+// Blocks yields every block until the end of input or the first error, which
+// is reported by Err afterwards. The sequence can be iterated only once.
+func (br *BlockReader) Blocks() iter.Seq2[BlockHeader, []byte] {
+	return func(yield func(BlockHeader, []byte) bool) {
+		if _, err := br.Version(); err != nil {
+			return
+		}
+
+		for br.err == nil {
+			var rawHeader [5]byte
+			if _, err := io.ReadFull(br.r, rawHeader[:]); err != nil {
+				if err == io.ErrUnexpectedEOF {
+					br.err = fmt.Errorf("Truncated block header")
+				} else if err != io.EOF {
+					br.err = err
+				}
+				return
+			}
+
+			header := BlockHeader{
+				Id:     rawHeader[0],
+				Length: binary.LittleEndian.Uint32(rawHeader[1:5]),
+			}
+
+			// Length is not trusted for allocation, corrupted files claim gigabytes
+			var payload bytes.Buffer
+			if n, err := io.CopyN(&payload, br.r, int64(header.Length)); err != nil {
+				if err == io.EOF {
+					br.err = fmt.Errorf("Block %v is truncated: %v of %v bytes", header.Id, n, header.Length)
+				} else {
+					br.err = err
+				}
+				return
+			}
+
+			if !yield(header, payload.Bytes()) {
+				return
+			}
+		}
+	}
+}
+
+func (br *BlockReader) Err() error {
+	return br.err
+}
+
+// ReadBlocks is a shorthand for NewBlockReader(r).Blocks() for callers that
+// do not care why the sequence ended
+func ReadBlocks(r io.Reader) iter.Seq2[BlockHeader, []byte] {
+	return NewBlockReader(r).Blocks()
+}
+
+func WriteHeader(w io.Writer, version uint8) error {
+	_, err := w.Write([]byte{'B', 'M', 'F', version})
+	return err
+}
+
+func WriteBlock(w io.Writer, id uint8, payload []byte) error {
+	var header [5]byte
+	header[0] = id
+	binary.LittleEndian.PutUint32(header[1:5], uint32(len(payload)))
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
@@ -1,16 +1,12 @@
 package bmfont
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"strings"
-
-	"golang.org/x/text/encoding"
-	"golang.org/x/text/encoding/charmap"
 )
 
-var Encoding encoding.Encoding = charmap.Windows1252
-
 const (
 	INFO_BITFIELD_SMOOTH  = 1 << (7 - iota) // Set if smoothing was turned on
 	INFO_BITFIELD_UNICODE                   // Set if it is the unicode charset
@@ -31,6 +27,13 @@ const (
 	BLOCK_TYPE_KERNING_PAIRS = 5
 )
 
+// Extension blocks, not part of the AngelCode format. Readers that follow the
+// spec skip block ids they do not know.
+const (
+	BLOCK_TYPE_EXT_PAGE_CHANNELS = 0x80 // One channel mask per page, see Font.PageChnls
+	BLOCK_TYPE_EXT_ANIMATIONS    = 0x81 // Animation frames of chars, see Font.Animations
+)
+
 type Info struct {
 	FontSize     int16  // The size of the true type font
 	BitField     uint8  // Use INFO_BITFIELD_ consts
@@ -47,7 +50,11 @@ type Info struct {
 	FontName     string // This is the name of the true type font
 }
 
-func (i *Info) fromBinary(b []byte) error {
+func (i *Info) fromBinary(b []byte, opts *Options) error {
+	if len(b) < 14 {
+		return fmt.Errorf("Block too short: %v bytes", len(b))
+	}
+
 	i.FontSize = int16(binary.LittleEndian.Uint16(b[0:2]))
 	i.BitField = b[2]
 	i.CharSet = b[3]
@@ -61,15 +68,38 @@ func (i *Info) fromBinary(b []byte) error {
 	i.SpacingVert = b[12]
 	i.Outline = b[13]
 
-	fontBuf := make([]byte, ((len(b)-14)*5)/2)
-	if nDst, _, err := Encoding.NewDecoder().Transform(fontBuf, b[14:len(b)], true); err != nil {
+	// Charset is known only now, so the name is decoded last
+	if fontName, err := opts.encodingFor(i).NewDecoder().Bytes(b[14:]); err != nil {
 		return fmt.Errorf("Error parsing info section font name: %v", err)
 	} else {
-		i.FontName = string(fontBuf[:nDst])
+		i.FontName = strings.TrimRight(string(fontName), "\x00")
 	}
 	return nil
 }
 
+func (i *Info) toBinary(opts *Options) ([]byte, error) {
+	fontName, err := opts.encodingFor(i).NewEncoder().Bytes([]byte(i.FontName))
+	if err != nil {
+		return nil, fmt.Errorf("Error encoding info section font name: %v", err)
+	}
+
+	b := make([]byte, 14, 14+len(fontName)+1)
+	binary.LittleEndian.PutUint16(b[0:2], uint16(i.FontSize))
+	b[2] = i.BitField
+	b[3] = i.CharSet
+	binary.LittleEndian.PutUint16(b[4:6], i.StretchH)
+	b[6] = i.Aa
+	b[7] = i.PaddingUp
+	b[8] = i.PaddingRight
+	b[9] = i.PaddingDown
+	b[10] = i.PaddingLeft
+	b[11] = i.SpacingHoriz
+	b[12] = i.SpacingVert
+	b[13] = i.Outline
+	b = append(b, fontName...)
+	return append(b, 0), nil
+}
+
 type Common struct {
 	LineHeight uint16
 	Base       uint16
@@ -84,6 +114,10 @@ type Common struct {
 }
 
 func (c *Common) fromBinary(b []byte) error {
+	if len(b) < 15 {
+		return fmt.Errorf("Block too short: %v bytes", len(b))
+	}
+
 	c.LineHeight = binary.LittleEndian.Uint16(b[0:2])
 	c.Base = binary.LittleEndian.Uint16(b[2:4])
 	c.ScaleW = binary.LittleEndian.Uint16(b[4:6])
@@ -97,6 +131,21 @@ func (c *Common) fromBinary(b []byte) error {
 	return nil
 }
 
+func (c *Common) toBinary() []byte {
+	b := make([]byte, 15)
+	binary.LittleEndian.PutUint16(b[0:2], c.LineHeight)
+	binary.LittleEndian.PutUint16(b[2:4], c.Base)
+	binary.LittleEndian.PutUint16(b[4:6], c.ScaleW)
+	binary.LittleEndian.PutUint16(b[6:8], c.ScaleH)
+	binary.LittleEndian.PutUint16(b[8:10], c.Pages)
+	b[10] = c.BitField
+	b[11] = c.AlphaChnl
+	b[12] = c.RedChnl
+	b[13] = c.GreenChnl
+	b[14] = c.BlueChnl
+	return b
+}
+
 type Char struct {
 	Id       uint32
 	X        uint16
@@ -111,6 +160,10 @@ type Char struct {
 }
 
 func (c *Char) fromBinary(b []byte) error {
+	if len(b) < 20 {
+		return fmt.Errorf("Too short: %v bytes", len(b))
+	}
+
 	c.Id = binary.LittleEndian.Uint32(b[0:4])
 	c.X = binary.LittleEndian.Uint16(b[4:6])
 	c.Y = binary.LittleEndian.Uint16(b[6:8])
@@ -124,6 +177,19 @@ func (c *Char) fromBinary(b []byte) error {
 	return nil
 }
 
+func (c *Char) toBinary(b []byte) {
+	binary.LittleEndian.PutUint32(b[0:4], c.Id)
+	binary.LittleEndian.PutUint16(b[4:6], c.X)
+	binary.LittleEndian.PutUint16(b[6:8], c.Y)
+	binary.LittleEndian.PutUint16(b[8:10], c.Width)
+	binary.LittleEndian.PutUint16(b[10:12], c.Height)
+	binary.LittleEndian.PutUint16(b[12:14], uint16(c.Xoffset))
+	binary.LittleEndian.PutUint16(b[14:16], uint16(c.Yoffset))
+	binary.LittleEndian.PutUint16(b[16:18], uint16(c.Xadvance))
+	b[18] = c.Page
+	b[19] = c.Chnl
+}
+
 type KerningPair struct {
 	First  uint32
 	Second uint32
@@ -131,43 +197,152 @@ type KerningPair struct {
 }
 
 func (kp *KerningPair) fromBinary(b []byte) error {
+	if len(b) < 10 {
+		return fmt.Errorf("Too short: %v bytes", len(b))
+	}
+
 	kp.First = binary.LittleEndian.Uint32(b[0:4])
 	kp.Second = binary.LittleEndian.Uint32(b[4:8])
 	kp.Amount = binary.LittleEndian.Uint16(b[8:10])
 	return nil
 }
 
+func (kp *KerningPair) toBinary(b []byte) {
+	binary.LittleEndian.PutUint32(b[0:4], kp.First)
+	binary.LittleEndian.PutUint32(b[4:8], kp.Second)
+	binary.LittleEndian.PutUint16(b[8:10], kp.Amount)
+}
+
 type Font struct {
 	Info         *Info
 	Common       *Common
 	Pages        []string
 	Chars        []Char
 	KerningPairs []KerningPair
+
+	DistanceField *DistanceField // Only present in fonts read from JSON
+
+	// Channel mask per page overriding Char.Chnl of chars on that page, 0 means
+	// no override. Lets an atlas mix e.g. an alpha-only glyph page with a
+	// color emoji page.
+	PageChnls []uint8
+
+	// Frames of animated chars by char id. The char itself holds the
+	// metrics and the first frame for renderers unaware of animations.
+	Animations map[uint32][]AnimationFrame
+
+	// Records changes made by the editing methods when set, see Journal
+	Journal *Journal
 }
 
 func NewFont() *Font {
 	return &Font{}
 }
 
-func (f *Font) FromBuffer(b []byte) error {
-	if b[0] != 'B' || b[1] != 'M' || b[2] != 'F' {
-		return fmt.Errorf("Invalid identifier %v", b[:3])
+// CharChnl returns the channels the char is stored in, taking page overrides
+// into account
+func (f *Font) CharChnl(c *Char) uint8 {
+	if int(c.Page) < len(f.PageChnls) && f.PageChnls[c.Page] != 0 {
+		return f.PageChnls[c.Page]
+	}
+	return c.Chnl
+}
+
+// FindChar returns the char with the given id or nil
+func (f *Font) FindChar(id uint32) *Char {
+	for i := range f.Chars {
+		if f.Chars[i].Id == id {
+			return &f.Chars[i]
+		}
+	}
+	return nil
+}
+
+// Index into Chars by id. With duplicate ids the first char wins, as with
+// FindChar.
+func (f *Font) charIndexes() map[uint32]int {
+	index := make(map[uint32]int, len(f.Chars))
+	for i, c := range f.Chars {
+		if _, ok := index[c.Id]; !ok {
+			index[c.Id] = i
+		}
 	}
+	return index
+}
 
-	if b[3] != 3 {
-		return fmt.Errorf("Unsupported version %v", b[4])
+// KerningAmount returns how much the x position should be adjusted when
+// drawing second right after first
+func (f *Font) KerningAmount(first, second uint32) int16 {
+	for _, kp := range f.KerningPairs {
+		if kp.First == first && kp.Second == second {
+			return int16(kp.Amount)
+		}
 	}
+	return 0
+}
 
-	floatBuffer := b[4:]
-	for len(floatBuffer) > 4 {
-		blockId := floatBuffer[0]
-		blockLenght := binary.LittleEndian.Uint32(floatBuffer[1:5])
-		blockData := floatBuffer[5 : 5+blockLenght]
+// lineRune maps a rune of a single unbroken line to the char id drawn for it.
+// Soft hyphen, zero width space and word joiner are invisible within a line
+// whether the font has glyphs for them or not, and do not interrupt kerning.
+// No-break space falls back to the space char.
+func lineRune(r rune, hasChar func(id uint32) bool) (id uint32, visible bool) {
+	switch r {
+	case '\u00ad', '\u200b', '\u2060':
+		return 0, false
+	case '\u00a0':
+		if !hasChar(uint32(r)) {
+			return ' ', true
+		}
+	}
+	return uint32(r), true
+}
+
+func (f *Font) hasChar(id uint32) bool {
+	return f.FindChar(id) != nil
+}
 
-		switch blockId {
+// Width returns the advance of a single line of text. Runes without a char
+// are skipped. Only integer math is used, so the result is identical on every
+// architecture and matches TextMetrics.Width for the same font.
+func (f *Font) Width(text string) int {
+	pen, prev := 0, uint32(0)
+	for _, r := range text {
+		id, visible := lineRune(r, f.hasChar)
+		if !visible {
+			continue
+		}
+		if c := f.FindChar(id); c != nil {
+			pen += int(f.KerningAmount(prev, c.Id)) + int(c.Xadvance)
+		}
+		prev = id
+	}
+	return pen
+}
+
+func (f *Font) FromBuffer(b []byte) error {
+	return f.FromBufferWithOptions(b, nil)
+}
+
+func (f *Font) FromBufferWithOptions(b []byte, opts *Options) error {
+	return countParse(len(b), f.fromBuffer(b, opts))
+}
+
+func (f *Font) fromBuffer(b []byte, opts *Options) error {
+	f.Journal.reset()
+	br := NewBlockReader(bytes.NewReader(b))
+	if version, err := br.Version(); err != nil {
+		return err
+	} else if version != 3 {
+		return fmt.Errorf("Unsupported version %v", version)
+	}
+
+	for header, blockData := range br.Blocks() {
+		blockLenght := header.Length
+
+		switch header.Id {
 		case BLOCK_TYPE_INFO:
 			f.Info = &Info{}
-			if err := f.Info.fromBinary(blockData); err != nil {
+			if err := f.Info.fromBinary(blockData, opts); err != nil {
 				return fmt.Errorf("Error parsing info block: %v", err)
 			}
 		case BLOCK_TYPE_COMMON:
@@ -176,14 +351,16 @@ func (f *Font) FromBuffer(b []byte) error {
 				return fmt.Errorf("Error parsing common block: %v", err)
 			}
 		case BLOCK_TYPE_PAGES:
-			fontBuf := make([]byte, (blockLenght*5)/2)
-			if nDst, _, err := Encoding.NewDecoder().Transform(fontBuf, blockData, false); err != nil {
-				return fmt.Errorf("Error parsing pages text: %v")
+			if pagesText, err := opts.encodingFor(f.Info).NewDecoder().Bytes(blockData); err != nil {
+				return fmt.Errorf("Error parsing pages text: %v", err)
 			} else {
-				f.Pages = strings.Split(string(fontBuf[:nDst]), "\x00")
+				f.Pages = strings.Split(string(pagesText), "\x00")
 				f.Pages = f.Pages[:len(f.Pages)-1]
 			}
 		case BLOCK_TYPE_CHARS:
+			if blockLenght%20 != 0 {
+				return fmt.Errorf("Chars block length %v is not a multiple of 20", blockLenght)
+			}
 			charsCnt := blockLenght / 20
 			f.Chars = make([]Char, charsCnt)
 			for i := range f.Chars {
@@ -192,6 +369,9 @@ func (f *Font) FromBuffer(b []byte) error {
 				}
 			}
 		case BLOCK_TYPE_KERNING_PAIRS:
+			if blockLenght%10 != 0 {
+				return fmt.Errorf("Kerning pairs block length %v is not a multiple of 10", blockLenght)
+			}
 			kerningPairsCnt := blockLenght / 10
 			f.KerningPairs = make([]KerningPair, kerningPairsCnt)
 			for i := range f.KerningPairs {
@@ -199,14 +379,82 @@ func (f *Font) FromBuffer(b []byte) error {
 					return fmt.Errorf("Error parsing kerning pair %v: %v", i, err)
 				}
 			}
+		case BLOCK_TYPE_EXT_PAGE_CHANNELS:
+			f.PageChnls = append([]uint8(nil), blockData...)
+		case BLOCK_TYPE_EXT_ANIMATIONS:
+			if animations, err := animationsFromBinary(blockData); err != nil {
+				return fmt.Errorf("Error parsing animations block: %v", err)
+			} else {
+				f.Animations = animations
+			}
 		}
-
-		floatBuffer = floatBuffer[5+blockLenght:]
 	}
-	return nil
+	return br.Err()
 }
 
 func NewFontFromBuf(b []byte) (*Font, error) {
+	return NewFontFromBufWithOptions(b, nil)
+}
+
+func NewFontFromBufWithOptions(b []byte, opts *Options) (*Font, error) {
 	f := NewFont()
-	return f, f.FromBuffer(b)
+	return f, f.FromBufferWithOptions(b, opts)
+}
+
+func (f *Font) ToBuffer() ([]byte, error) {
+	return f.ToBufferWithOptions(nil)
+}
+
+func (f *Font) ToBufferWithOptions(opts *Options) ([]byte, error) {
+	// Writes to bytes.Buffer never fail, so write errors are not checked
+	var buf bytes.Buffer
+	WriteHeader(&buf, 3)
+
+	if f.Info != nil {
+		blockData, err := f.Info.toBinary(opts)
+		if err != nil {
+			return nil, fmt.Errorf("Error writing info block: %v", err)
+		}
+		WriteBlock(&buf, BLOCK_TYPE_INFO, blockData)
+	}
+
+	if f.Common != nil {
+		WriteBlock(&buf, BLOCK_TYPE_COMMON, f.Common.toBinary())
+	}
+
+	if len(f.Pages) != 0 {
+		var pagesData []byte
+		for _, page := range f.Pages {
+			pageName, err := opts.encodingFor(f.Info).NewEncoder().Bytes([]byte(page))
+			if err != nil {
+				return nil, fmt.Errorf("Error encoding page name %q: %v", page, err)
+			}
+			pagesData = append(append(pagesData, pageName...), 0)
+		}
+		WriteBlock(&buf, BLOCK_TYPE_PAGES, pagesData)
+	}
+
+	charsData := make([]byte, len(f.Chars)*20)
+	for i := range f.Chars {
+		f.Chars[i].toBinary(charsData[i*20 : i*20+20])
+	}
+	WriteBlock(&buf, BLOCK_TYPE_CHARS, charsData)
+
+	if len(f.KerningPairs) != 0 {
+		kerningPairsData := make([]byte, len(f.KerningPairs)*10)
+		for i := range f.KerningPairs {
+			f.KerningPairs[i].toBinary(kerningPairsData[i*10 : i*10+10])
+		}
+		WriteBlock(&buf, BLOCK_TYPE_KERNING_PAIRS, kerningPairsData)
+	}
+
+	if len(f.PageChnls) != 0 {
+		WriteBlock(&buf, BLOCK_TYPE_EXT_PAGE_CHANNELS, f.PageChnls)
+	}
+
+	if len(f.Animations) != 0 {
+		WriteBlock(&buf, BLOCK_TYPE_EXT_ANIMATIONS, animationsToBinary(f.Animations))
+	}
+
+	return buf.Bytes(), nil
 }
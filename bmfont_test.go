@@ -0,0 +1,73 @@
+package bmfont
+
+import (
+	"testing"
+)
+
+func TestFromBufferMalformedBlocks(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		data []byte
+	}{
+		{"short header", []byte("BM")},
+		{"short common", []byte("BMF\x03\x02\x04\x00\x00\x00\x00\x00\x00\x00")},
+		{"short info", []byte("BMF\x03\x01\x02\x00\x00\x00\x00\x00")},
+		{"chars not multiple of 20", append([]byte("BMF\x03\x04\x15\x00\x00\x00"), make([]byte, 21)...)},
+		{"kerning not multiple of 10", append([]byte("BMF\x03\x05\x0b\x00\x00\x00"), make([]byte, 11)...)},
+		{"truncated block", []byte("BMF\x03\x04\x14\x00\x00\x00\x00")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := NewFont().FromBuffer(tc.data); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestWidthInvisibleAndNoBreakSpace(t *testing.T) {
+	base := Font{
+		Chars: []Char{
+			{Id: 'A', Xadvance: 10},
+			{Id: 'V', Xadvance: 11},
+			{Id: ' ', Xadvance: 4},
+			{Id: '\u00ad', Xadvance: 5}, // Never drawn within a line
+		},
+		KerningPairs: []KerningPair{
+			{First: 'A', Second: 'V', Amount: uint16(0xffff - 2)}, // -3
+			{First: 'A', Second: ' ', Amount: uint16(0xffff)},     // -1
+		},
+	}
+	withNBSP := base
+	withNBSP.Chars = append(append([]Char(nil), base.Chars...), Char{Id: '\u00a0', Xadvance: 6})
+
+	for _, tc := range []struct {
+		text     string
+		width    int // Font without a no-break space char
+		withNBSP int
+	}{
+		{"AV", 18, 18},
+		{"A\u00adV", 18, 18},
+		{"A\u200bV", 18, 18},
+		{"A\u2060V", 18, 18},
+		{"\u00ad\u200b\u2060", 0, 0},
+		{"A\u00a0", 13, 16},
+		{"A\u00a0V", 24, 27},
+		{"A\u00ad\u00a0", 13, 16},
+	} {
+		for _, font := range []struct {
+			name  string
+			f     *Font
+			width int
+		}{
+			{"font", &base, tc.width},
+			{"font with nbsp", &withNBSP, tc.withNBSP},
+		} {
+			if got := font.f.Width(tc.text); got != font.width {
+				t.Errorf("%v: Width(%q) = %v, want %v", font.name, tc.text, got, font.width)
+			}
+			if got := font.f.TextMetrics().Width(tc.text); got != font.width {
+				t.Errorf("%v metrics: Width(%q) = %v, want %v", font.name, tc.text, got, font.width)
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package bmfont
+
+import "strings"
+
+// Channel bits of Char.Chnl and Font.PageChnls. Binary, JSON and CSV all
+// store the same mask, a char in a packed font uses exactly one bit.
+const (
+	CHNL_BLUE  = 1
+	CHNL_GREEN = 2
+	CHNL_RED   = 4
+	CHNL_ALPHA = 8
+	CHNL_ALL   = CHNL_BLUE | CHNL_GREEN | CHNL_RED | CHNL_ALPHA
+)
+
+// Channel bits in the order they are packed
+var packedChannels = []uint8{CHNL_BLUE, CHNL_GREEN, CHNL_RED, CHNL_ALPHA}
+
+// Channels is a channel mask with helpers for querying it
+type Channels uint8
+
+// Has reports whether every channel in chnl is set
+func (c Channels) Has(chnl uint8) bool {
+	return uint8(c)&chnl == chnl
+}
+
+func (c Channels) Count() int {
+	n := 0
+	for _, bit := range packedChannels {
+		if c.Has(bit) {
+			n++
+		}
+	}
+	return n
+}
+
+// Packed reports whether the glyph lives in a single channel
+func (c Channels) Packed() bool {
+	return c.Count() == 1
+}
+
+// String lists the channels as "rgba" letters, "-" for none
+func (c Channels) String() string {
+	var sb strings.Builder
+	for _, ch := range []struct {
+		bit    uint8
+		letter byte
+	}{{CHNL_RED, 'r'}, {CHNL_GREEN, 'g'}, {CHNL_BLUE, 'b'}, {CHNL_ALPHA, 'a'}} {
+		if c.Has(ch.bit) {
+			sb.WriteByte(ch.letter)
+		}
+	}
+	if sb.Len() == 0 {
+		return "-"
+	}
+	return sb.String()
+}
+
+// glyphChannel returns the single channel to read glyph coverage from for a
+// char stored in chnl. Glyphs of fonts that are not packed are in alpha,
+// unless the export set alpha to zero or one.
+func (f *Font) glyphChannel(chnl uint8) uint8 {
+	if chnl != CHNL_ALL && chnl != 0 {
+		return chnl
+	}
+	if f.Common != nil && (f.Common.AlphaChnl == 3 || f.Common.AlphaChnl == 4) {
+		return CHNL_RED
+	}
+	return CHNL_ALPHA
+}
+
+// Channels returns the channels the char occupies, taking page overrides
+// into account. A mask of 0, written by some tools for unpacked fonts, is
+// treated as all channels.
+func (f *Font) Channels(c *Char) Channels {
+	chnl := f.CharChnl(c)
+	if chnl == 0 {
+		chnl = CHNL_ALL
+	}
+	return Channels(chnl)
+}
@@ -0,0 +1,111 @@
+package bmfont
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// Packed font with one char per channel on page 0, an unpacked char on page
+// 1 and page 2 overriding every char on it to red
+func packedChannelsFont() *Font {
+	return &Font{
+		Common: &Common{LineHeight: 16, Base: 12, ScaleW: 64, ScaleH: 64, Pages: 3, BitField: COMMON_BITFIELD_PACKED},
+		Pages:  []string{"packed_0.png", "packed_1.png", "packed_2.png"},
+		Chars: []Char{
+			{Id: 'b', Width: 8, Height: 8, Xadvance: 8, Chnl: CHNL_BLUE},
+			{Id: 'g', Width: 8, Height: 8, Xadvance: 8, Chnl: CHNL_GREEN},
+			{Id: 'r', Width: 8, Height: 8, Xadvance: 8, Chnl: CHNL_RED},
+			{Id: 'a', Width: 8, Height: 8, Xadvance: 8, Chnl: CHNL_ALPHA},
+			{Id: 'A', Width: 8, Height: 8, Xadvance: 8, Page: 1, Chnl: CHNL_ALL},
+			{Id: 'X', Width: 8, Height: 8, Xadvance: 8, Page: 2, Chnl: CHNL_ALPHA},
+		},
+		PageChnls: []uint8{0, 0, CHNL_RED},
+	}
+}
+
+var packedChannelsWant = map[uint32]string{'b': "b", 'g': "g", 'r': "r", 'a': "a", 'A': "rgba", 'X': "r"}
+
+func checkPackedChannels(t *testing.T, f *Font) {
+	t.Helper()
+	src := packedChannelsFont()
+	if !reflect.DeepEqual(f.Chars, src.Chars) {
+		t.Errorf("chars %+v, want %+v", f.Chars, src.Chars)
+	}
+	for i := range f.Chars {
+		c := &f.Chars[i]
+		if got := f.Channels(c).String(); got != packedChannelsWant[c.Id] {
+			t.Errorf("char %q channels %v, want %v", rune(c.Id), got, packedChannelsWant[c.Id])
+		}
+	}
+}
+
+func TestChannelsBinaryRoundTrip(t *testing.T) {
+	b, err := packedChannelsFont().ToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := NewFontFromBuf(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkPackedChannels(t, f)
+	if !reflect.DeepEqual(f.PageChnls, packedChannelsFont().PageChnls) {
+		t.Errorf("page channels %v", f.PageChnls)
+	}
+}
+
+func TestChannelsJSONRoundTrip(t *testing.T) {
+	b, err := json.Marshal(packedChannelsFont())
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := NewFontFromJSON(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkPackedChannels(t, f)
+	if !reflect.DeepEqual(f.PageChnls, packedChannelsFont().PageChnls) {
+		t.Errorf("page channels %v", f.PageChnls)
+	}
+}
+
+// CSV only holds chars, page overrides stay with the font it is imported into
+func TestChannelsCSVRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := packedChannelsFont().ExportCharsCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	f := packedChannelsFont()
+	f.Chars = nil
+	if err := f.ImportCharsCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	checkPackedChannels(t, f)
+}
+
+func TestChannels(t *testing.T) {
+	for _, tc := range []struct {
+		chnl   uint8
+		count  int
+		packed bool
+		str    string
+	}{
+		{0, 0, false, "-"},
+		{CHNL_BLUE, 1, true, "b"},
+		{CHNL_GREEN, 1, true, "g"},
+		{CHNL_RED, 1, true, "r"},
+		{CHNL_ALPHA, 1, true, "a"},
+		{CHNL_RED | CHNL_GREEN, 2, false, "rg"},
+		{CHNL_ALL, 4, false, "rgba"},
+	} {
+		c := Channels(tc.chnl)
+		if c.Count() != tc.count || c.Packed() != tc.packed || c.String() != tc.str {
+			t.Errorf("Channels(%v): count %v, packed %v, string %q", tc.chnl, c.Count(), c.Packed(), c.String())
+		}
+		if !c.Has(tc.chnl) || (tc.chnl != CHNL_ALL && c.Has(CHNL_ALL)) {
+			t.Errorf("Channels(%v).Has is wrong", tc.chnl)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package bmfont
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var csvColumns = []string{"id", "char", "x", "y", "width", "height", "xoffset", "yoffset", "xadvance", "page", "chnl"}
+
+// ExportCharsCSV writes every char as a CSV row with a header line. The char
+// column is only there for people editing the file and is ignored on import.
+func (f *Font) ExportCharsCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvColumns); err != nil {
+		return err
+	}
+
+	for _, c := range f.Chars {
+		record := []string{
+			strconv.FormatUint(uint64(c.Id), 10),
+			string(rune(c.Id)),
+			strconv.Itoa(int(c.X)),
+			strconv.Itoa(int(c.Y)),
+			strconv.Itoa(int(c.Width)),
+			strconv.Itoa(int(c.Height)),
+			strconv.Itoa(int(c.Xoffset)),
+			strconv.Itoa(int(c.Yoffset)),
+			strconv.Itoa(int(c.Xadvance)),
+			strconv.Itoa(int(c.Page)),
+			strconv.Itoa(int(c.Chnl)),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func parseCSVField(value string, bits int, signed bool) (int64, error) {
+	value = strings.TrimSpace(value)
+	if signed {
+		return strconv.ParseInt(value, 10, bits)
+	}
+	v, err := strconv.ParseUint(value, 10, bits)
+	return int64(v), err
+}
+
+// Columns ImportCharsCSV applies besides id
+var csvFields = []struct {
+	name   string
+	bits   int
+	signed bool
+	set    func(c *Char, v int64)
+}{
+	{"x", 16, false, func(c *Char, v int64) { c.X = uint16(v) }},
+	{"y", 16, false, func(c *Char, v int64) { c.Y = uint16(v) }},
+	{"width", 16, false, func(c *Char, v int64) { c.Width = uint16(v) }},
+	{"height", 16, false, func(c *Char, v int64) { c.Height = uint16(v) }},
+	{"xoffset", 16, true, func(c *Char, v int64) { c.Xoffset = int16(v) }},
+	{"yoffset", 16, true, func(c *Char, v int64) { c.Yoffset = int16(v) }},
+	{"xadvance", 16, true, func(c *Char, v int64) { c.Xadvance = int16(v) }},
+	{"page", 8, false, func(c *Char, v int64) { c.Page = uint8(v) }},
+	{"chnl", 8, false, func(c *Char, v int64) { c.Chnl = uint8(v) }},
+}
+
+type csvRow struct {
+	id     uint32
+	values map[string]int64 // By csvFields name, only columns present in the row
+}
+
+// ImportCharsCSV merges chars from CSV produced by ExportCharsCSV back into
+// the font. Rows update the char with the same id or add a new one, chars
+// without a row are kept. Columns are matched by header name, so they can be
+// reordered or left out; missing columns keep the current values. The whole
+// file is parsed before anything is applied, so on error the font is left
+// unchanged.
+func (f *Font) ImportCharsCSV(r io.Reader) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return fmt.Errorf("Error reading csv header: %v", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		// Spreadsheets like to save with a byte order mark
+		name = strings.TrimPrefix(name, "\ufeff")
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["id"]; !ok {
+		return fmt.Errorf("Csv has no id column")
+	}
+
+	var rows []csvRow
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("Error reading csv line %v: %v", line, err)
+		}
+
+		get := func(name string, bits int, signed bool) (int64, bool, error) {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return 0, false, nil
+			}
+			v, err := parseCSVField(record[i], bits, signed)
+			if err != nil {
+				return 0, false, fmt.Errorf("Line %v column %v: %v", line, name, err)
+			}
+			return v, true, nil
+		}
+
+		id, ok, err := get("id", 32, false)
+		if err != nil {
+			return err
+		} else if !ok {
+			return fmt.Errorf("Line %v has no id", line)
+		}
+
+		row := csvRow{id: uint32(id), values: make(map[string]int64, len(csvFields))}
+		for _, field := range csvFields {
+			v, ok, err := get(field.name, field.bits, field.signed)
+			if err != nil {
+				return err
+			}
+			if ok {
+				row.values[field.name] = v
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	index := f.charIndexes()
+	for _, row := range rows {
+		c := Char{Id: row.id}
+		i, ok := index[row.id]
+		if ok {
+			c = f.Chars[i]
+		}
+		for _, field := range csvFields {
+			if v, ok := row.values[field.name]; ok {
+				field.set(&c, v)
+			}
+		}
+		if ok {
+			f.setCharAt(i, c)
+		} else {
+			index[row.id] = len(f.Chars)
+			f.AddChar(c)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package bmfont
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImportCharsCSVRejectsWithoutChanges(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		csv  string
+	}{
+		{"no id column", "x,y\n1,2\n"},
+		{"short row", "x,id\n3\n"},
+		{"empty id", "id,x\n,3\n"},
+		{"bad cell after good rows", "id,x\n65,1\n66,2\n67,bad\n"},
+		{"out of range", "id,page\n65,256\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &Font{Chars: []Char{{Id: 'A', X: 7}}}
+			want := []Char{{Id: 'A', X: 7}}
+			if err := f.ImportCharsCSV(strings.NewReader(tc.csv)); err == nil {
+				t.Errorf("expected an error")
+			}
+			if !reflect.DeepEqual(f.Chars, want) {
+				t.Errorf("chars changed to %+v", f.Chars)
+			}
+		})
+	}
+}
+
+func TestImportCharsCSVMerges(t *testing.T) {
+	f := &Font{Chars: []Char{{Id: 'A', X: 7, Y: 8}, {Id: 'B', X: 1}}}
+	err := f.ImportCharsCSV(strings.NewReader("\ufeffid,x,xoffset\n65,3,-2\n67,4,5\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Char{{Id: 'A', X: 3, Y: 8, Xoffset: -2}, {Id: 'B', X: 1}, {Id: 'C', X: 4, Xoffset: 5}}
+	if !reflect.DeepEqual(f.Chars, want) {
+		t.Errorf("got %+v, want %+v", f.Chars, want)
+	}
+}
+
+func TestImportCharsCSVDuplicateIds(t *testing.T) {
+	f := &Font{Chars: []Char{{Id: 'A', X: 1}, {Id: 'A', X: 2}}}
+	if err := f.ImportCharsCSV(strings.NewReader("id,x\n65,3\n")); err != nil {
+		t.Fatal(err)
+	}
+	if f.FindChar('A').X != 3 || f.Chars[1].X != 2 {
+		t.Errorf("merged into the wrong char: %+v", f.Chars)
+	}
+}
@@ -0,0 +1,187 @@
+package bmfont
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"os"
+)
+
+// EditSession wraps a font being edited by a kerning editor: it renders pair
+// previews and saves back to where the font came from. Undo and redo go
+// through the font journal, so they also cover edits made directly with the
+// Font editing methods.
+type EditSession struct {
+	Font   *Font
+	Pages  PageImages
+	Path   string
+	Format Format
+
+	saved int // Journal head at the last save
+}
+
+// NewEditSession starts a journal on the font unless it already has one
+func NewEditSession(f *Font, pages PageImages, path string, format Format) *EditSession {
+	if f.Journal == nil {
+		f.Journal = &Journal{}
+	}
+	return &EditSession{Font: f, Pages: pages, Path: path, Format: format, saved: f.Journal.Head()}
+}
+
+// LoadEditSession loads the font at path with its pages for editing
+func LoadEditSession(path string, opts *LoadOptions) (*EditSession, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	// Saves keep the format the font was loaded from
+	format, err := DetectFormat(b)
+	if err != nil {
+		return nil, fmt.Errorf("Error loading %v: %v", path, err)
+	}
+
+	f, pages, err := LoadFont(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	return NewEditSession(f, pages, path, format), nil
+}
+
+// ApplyKerning sets the kerning of a pair, 0 removes the pair. Clears the
+// redo history.
+func (s *EditSession) ApplyKerning(first, second uint32, amount int16) {
+	s.Font.SetKerning(first, second, amount)
+}
+
+func (s *EditSession) CanUndo() bool { return s.Font.Journal.CanUndo() }
+func (s *EditSession) CanRedo() bool { return s.Font.Journal.CanRedo() }
+
+func (s *EditSession) Undo() bool { return s.Font.Undo() }
+func (s *EditSession) Redo() bool { return s.Font.Redo() }
+
+// Dirty reports whether the font differs from the last saved state
+func (s *EditSession) Dirty() bool {
+	return s.saved != s.Font.Journal.Head()
+}
+
+// Save writes the font back to Path atomically, keeping a backup of the
+// previous version
+func (s *EditSession) Save() error {
+	if s.Path == "" {
+		return fmt.Errorf("Edit session has no path")
+	}
+	if err := s.Font.SaveFileAtomicWithOptions(s.Path, s.Format, &SaveOptions{Backup: true}); err != nil {
+		return err
+	}
+	s.saved = s.Font.Journal.Head()
+	return nil
+}
+
+// PreviewPair renders the two chars next to each other with the current
+// kerning, black on white, scaled up by scale with nearest neighbour so
+// single pixel changes stay visible
+func (s *EditSession) PreviewPair(first, second uint32, scale int) (*image.RGBA, error) {
+	f := s.Font
+	if f.Common == nil {
+		return nil, fmt.Errorf("Font has no common block")
+	}
+	if scale <= 0 {
+		scale = 1
+	}
+	text := string(rune(first)) + string(rune(second))
+
+	if f.DistanceField != nil {
+		margin := int(f.Common.LineHeight) / 2
+		left, right := f.lineExtent(text)
+		img := image.NewRGBA(image.Rect(0, 0, (right-left+2*margin)*scale, int(f.Common.LineHeight)*scale))
+		for i := range img.Pix {
+			img.Pix[i] = 255
+		}
+		f.drawSDFLine(img, s.Pages, text, float64(scale), (margin-left)*scale, 0)
+		return img, nil
+	}
+
+	return f.drawBitmapText(s.Pages, text, scale), nil
+}
+
+// Horizontal extent of the glyph boxes and pen positions of text, relative to
+// the first pen position. Unlike Width it covers glyphs pulled left of the
+// start by negative kerning or offsets.
+func (f *Font) lineExtent(text string) (left, right int) {
+	pen, prev := 0, uint32(0)
+	for _, r := range text {
+		id, visible := lineRune(r, f.hasChar)
+		if !visible {
+			continue
+		}
+		c := f.FindChar(id)
+		if c == nil {
+			prev = id
+			continue
+		}
+		pen += int(f.KerningAmount(prev, c.Id))
+		prev = c.Id
+		left = min(left, pen, pen+int(c.Xoffset))
+		right = max(right, pen, pen+int(c.Xoffset)+int(c.Width))
+		pen += int(c.Xadvance)
+		left, right = min(left, pen), max(right, pen)
+	}
+	return left, right
+}
+
+// Coverage of a bitmap glyph pixel, 0..1
+func bitmapCoverage(img image.Image, x, y int, chnl uint8) float64 {
+	return float64(sdfValue(img, x, y, chnl, "")) / 255
+}
+
+func (f *Font) drawBitmapText(pages PageImages, text string, scale int) *image.RGBA {
+	lineHeight := int(f.Common.LineHeight)
+	margin := lineHeight / 2
+	left, right := f.lineExtent(text)
+	img := image.NewRGBA(image.Rect(0, 0, (right-left+2*margin)*scale, lineHeight*scale))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+
+	pen, prev := margin-left, uint32(0)
+	for _, r := range text {
+		id, visible := lineRune(r, f.hasChar)
+		if !visible {
+			continue
+		}
+		c := f.FindChar(id)
+		if c == nil {
+			prev = id
+			continue
+		}
+		pen += int(f.KerningAmount(prev, c.Id))
+		prev = c.Id
+
+		if int(c.Page) < len(pages) && pages[c.Page] != nil {
+			page := pages[c.Page]
+			chnl := f.glyphChannel(f.CharChnl(c))
+			for gy := 0; gy < int(c.Height); gy++ {
+				for gx := 0; gx < int(c.Width); gx++ {
+					coverage := bitmapCoverage(page, page.Bounds().Min.X+int(c.X)+gx, page.Bounds().Min.Y+int(c.Y)+gy, chnl)
+					if coverage == 0 {
+						continue
+					}
+					dx, dy := (pen+int(c.Xoffset)+gx)*scale, (int(c.Yoffset)+gy)*scale
+					for y := dy; y < dy+scale; y++ {
+						for x := dx; x < dx+scale; x++ {
+							if !(image.Point{x, y}).In(img.Rect) {
+								continue
+							}
+							old := img.RGBAAt(x, y)
+							v := uint8(math.Round(float64(old.R) * (1 - coverage)))
+							img.SetRGBA(x, y, color.RGBA{v, v, v, 255})
+						}
+					}
+				}
+			}
+		}
+		pen += int(c.Xadvance)
+	}
+	return img
+}
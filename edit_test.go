@@ -0,0 +1,43 @@
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestPreviewPairNegativeKerning(t *testing.T) {
+	page := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 5; x++ {
+			page.SetNRGBA(x, y, color.NRGBA{255, 255, 255, 255})
+		}
+	}
+	f := &Font{
+		Common: &Common{LineHeight: 20, Base: 16, ScaleW: 8, ScaleH: 8, Pages: 1},
+		Pages:  []string{"kern_0.png"},
+		Chars: []Char{
+			{Id: 'A', Width: 5, Height: 5, Xadvance: 5, Chnl: CHNL_ALL},
+			{Id: 'V', Width: 5, Height: 5, Xadvance: 5, Chnl: CHNL_ALL},
+		},
+		KerningPairs: []KerningPair{{First: 'A', Second: 'V', Amount: uint16(0xffff - 100)}}, // -101
+	}
+	s := NewEditSession(f, PageImages{page}, "", FORMAT_BINARY)
+
+	img, err := s.PreviewPair('A', 'V', 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Rect.Min != (image.Point{}) || img.Rect.Dx() < 101*2 {
+		t.Fatalf("bounds %v", img.Rect)
+	}
+	dark := 0
+	for i := 0; i < len(img.Pix); i += 4 {
+		if img.Pix[i] == 0 {
+			dark++
+		}
+	}
+	if want := 2 * 5 * 5 * 2 * 2; dark != want {
+		t.Errorf("%v glyph pixels drawn, want %v", dark, want)
+	}
+}
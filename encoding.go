@@ -0,0 +1,110 @@
+package bmfont
+
+import (
+	"bytes"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Windows OEM charsets stored in Info.CharSet
+const (
+	CHARSET_ANSI        = 0
+	CHARSET_DEFAULT     = 1
+	CHARSET_SYMBOL      = 2
+	CHARSET_SHIFTJIS    = 128
+	CHARSET_HANGUL      = 129
+	CHARSET_GB2312      = 134
+	CHARSET_CHINESEBIG5 = 136
+	CHARSET_GREEK       = 161
+	CHARSET_TURKISH     = 162
+	CHARSET_VIETNAMESE  = 163
+	CHARSET_HEBREW      = 177
+	CHARSET_ARABIC      = 178
+	CHARSET_BALTIC      = 186
+	CHARSET_RUSSIAN     = 204
+	CHARSET_THAI        = 222
+	CHARSET_EASTEUROPE  = 238
+	CHARSET_OEM         = 255
+)
+
+// Deprecated: Encoding is only used as the fallback for ANSI and unknown
+// charsets. Set Options.Encoding for each parse instead.
+var Encoding encoding.Encoding = charmap.Windows1252
+
+// EncodingForCharSet returns the encoding BMFont uses for strings of a font
+// with the given charset
+func EncodingForCharSet(charSet uint8, unicodeBit bool) encoding.Encoding {
+	if unicodeBit {
+		return unicode.UTF8
+	}
+
+	switch charSet {
+	case CHARSET_SHIFTJIS:
+		return japanese.ShiftJIS
+	case CHARSET_HANGUL:
+		return korean.EUCKR
+	case CHARSET_GB2312:
+		return simplifiedchinese.GBK
+	case CHARSET_CHINESEBIG5:
+		return traditionalchinese.Big5
+	case CHARSET_GREEK:
+		return charmap.Windows1253
+	case CHARSET_TURKISH:
+		return charmap.Windows1254
+	case CHARSET_VIETNAMESE:
+		return charmap.Windows1258
+	case CHARSET_HEBREW:
+		return charmap.Windows1255
+	case CHARSET_ARABIC:
+		return charmap.Windows1256
+	case CHARSET_BALTIC:
+		return charmap.Windows1257
+	case CHARSET_RUSSIAN:
+		return charmap.Windows1251
+	case CHARSET_THAI:
+		return charmap.Windows874
+	case CHARSET_EASTEUROPE:
+		return charmap.Windows1250
+	case CHARSET_OEM:
+		return charmap.CodePage437
+	default:
+		return Encoding
+	}
+}
+
+func (opts *Options) encodingFor(info *Info) encoding.Encoding {
+	if opts != nil && opts.Encoding != nil {
+		return opts.Encoding
+	}
+	if info == nil {
+		return Encoding
+	}
+	return EncodingForCharSet(info.CharSet, info.BitField&INFO_BITFIELD_UNICODE != 0)
+}
+
+// textDescriptor returns text descriptor bytes as UTF-8 without a byte order
+// mark. Some Windows tools save with a UTF-8 BOM or as UTF-16, with or without
+// a BOM; UTF-16 without a BOM is recognized by the NUL byte of the first
+// ASCII char.
+func textDescriptor(b []byte) ([]byte, error) {
+	var dec *encoding.Decoder
+	switch {
+	case bytes.HasPrefix(b, []byte{0xef, 0xbb, 0xbf}):
+		return b[3:], nil
+	case bytes.HasPrefix(b, []byte{0xff, 0xfe}), bytes.HasPrefix(b, []byte{0xfe, 0xff}):
+		dec = unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM).NewDecoder()
+	case len(b) >= 2 && b[0] != 0 && b[1] == 0:
+		dec = unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewDecoder()
+	case len(b) >= 2 && b[0] == 0 && b[1] != 0:
+		dec = unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM).NewDecoder()
+	default:
+		return b, nil
+	}
+	return dec.Bytes(b)
+}
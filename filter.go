@@ -0,0 +1,282 @@
+package bmfont
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CharFilter reports whether a char matches a compiled filter expression
+type CharFilter func(c *Char) bool
+
+// Fields usable in filter expressions
+var charFilterFields = map[string]func(c *Char) int64{
+	"id":       func(c *Char) int64 { return int64(c.Id) },
+	"x":        func(c *Char) int64 { return int64(c.X) },
+	"y":        func(c *Char) int64 { return int64(c.Y) },
+	"width":    func(c *Char) int64 { return int64(c.Width) },
+	"height":   func(c *Char) int64 { return int64(c.Height) },
+	"xoffset":  func(c *Char) int64 { return int64(c.Xoffset) },
+	"yoffset":  func(c *Char) int64 { return int64(c.Yoffset) },
+	"xadvance": func(c *Char) int64 { return int64(c.Xadvance) },
+	"page":     func(c *Char) int64 { return int64(c.Page) },
+	"chnl":     func(c *Char) int64 { return int64(c.Chnl) },
+}
+
+type filterNode func(c *Char) int64
+
+// Two char operators go first so they win over their one char prefixes
+var filterOperators = []string{
+	"&&", "||", "==", "!=", "<=", ">=",
+	"<", ">", "!", "+", "-", "*", "/", "%", "(", ")",
+}
+
+type filterParser struct {
+	src    string
+	tokens []string
+	pos    int
+}
+
+func (p *filterParser) tokenize() error {
+	s := p.src
+	for len(s) != 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		switch {
+		case unicode.IsSpace(r):
+			s = s[size:]
+		case unicode.IsDigit(r):
+			end := strings.IndexFunc(s, func(r rune) bool { return !unicode.IsDigit(r) && !unicode.IsLetter(r) })
+			if end == -1 {
+				end = len(s)
+			}
+			p.tokens = append(p.tokens, s[:end])
+			s = s[end:]
+		case unicode.IsLetter(r) || r == '_':
+			end := strings.IndexFunc(s, func(r rune) bool { return !unicode.IsDigit(r) && !unicode.IsLetter(r) && r != '_' })
+			if end == -1 {
+				end = len(s)
+			}
+			p.tokens = append(p.tokens, s[:end])
+			s = s[end:]
+		case r == '\'':
+			end := strings.IndexByte(s[1:], '\'')
+			if end == -1 {
+				return fmt.Errorf("Unterminated char literal")
+			}
+			p.tokens = append(p.tokens, s[:end+2])
+			s = s[end+2:]
+		default:
+			op := ""
+			for _, candidate := range filterOperators {
+				if strings.HasPrefix(s, candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return fmt.Errorf("Unexpected %q", s[:size])
+			}
+			p.tokens = append(p.tokens, op)
+			s = s[len(op):]
+		}
+	}
+	return nil
+}
+
+func (p *filterParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *filterParser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func boolValue(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Binary operator levels from the loosest to the tightest binding
+var filterLevels = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!="},
+	{"<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func binaryNode(op string, l, r filterNode) filterNode {
+	switch op {
+	case "||":
+		return func(c *Char) int64 { return boolValue(l(c) != 0 || r(c) != 0) }
+	case "&&":
+		return func(c *Char) int64 { return boolValue(l(c) != 0 && r(c) != 0) }
+	case "==":
+		return func(c *Char) int64 { return boolValue(l(c) == r(c)) }
+	case "!=":
+		return func(c *Char) int64 { return boolValue(l(c) != r(c)) }
+	case "<":
+		return func(c *Char) int64 { return boolValue(l(c) < r(c)) }
+	case "<=":
+		return func(c *Char) int64 { return boolValue(l(c) <= r(c)) }
+	case ">":
+		return func(c *Char) int64 { return boolValue(l(c) > r(c)) }
+	case ">=":
+		return func(c *Char) int64 { return boolValue(l(c) >= r(c)) }
+	case "+":
+		return func(c *Char) int64 { return l(c) + r(c) }
+	case "-":
+		return func(c *Char) int64 { return l(c) - r(c) }
+	case "*":
+		return func(c *Char) int64 { return l(c) * r(c) }
+	case "/":
+		return func(c *Char) int64 {
+			if d := r(c); d != 0 {
+				return l(c) / d
+			}
+			return 0
+		}
+	default: // "%"
+		return func(c *Char) int64 {
+			if d := r(c); d != 0 {
+				return l(c) % d
+			}
+			return 0
+		}
+	}
+}
+
+func (p *filterParser) parseLevel(level int) (filterNode, error) {
+	if level == len(filterLevels) {
+		return p.parseUnary()
+	}
+
+	left, err := p.parseLevel(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek()
+		found := false
+		for _, candidate := range filterLevels[level] {
+			found = found || op == candidate
+		}
+		if !found {
+			return left, nil
+		}
+		p.next()
+
+		right, err := p.parseLevel(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode(op, left, right)
+	}
+}
+
+func (p *filterParser) parseUnary() (filterNode, error) {
+	switch p.peek() {
+	case "!":
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(c *Char) int64 { return boolValue(operand(c) == 0) }, nil
+	case "-":
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(c *Char) int64 { return -operand(c) }, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *filterParser) parsePrimary() (filterNode, error) {
+	t := p.next()
+	switch {
+	case t == "":
+		return nil, fmt.Errorf("Unexpected end of expression")
+	case t == "(":
+		node, err := p.parseLevel(0)
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("Missing )")
+		}
+		return node, nil
+	case t[0] == '\'':
+		r, size := utf8.DecodeRuneInString(t[1:])
+		if size+2 != len(t) {
+			return nil, fmt.Errorf("Invalid char literal %v", t)
+		}
+		return func(c *Char) int64 { return int64(r) }, nil
+	case unicode.IsDigit(rune(t[0])):
+		v, err := strconv.ParseInt(t, 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid number %v", t)
+		}
+		return func(c *Char) int64 { return v }, nil
+	default:
+		field, ok := charFilterFields[strings.ToLower(t)]
+		if !ok {
+			return nil, fmt.Errorf("Unknown field %q", t)
+		}
+		return field, nil
+	}
+}
+
+// CompileCharFilter compiles a filter expression such as
+//
+//	id >= 0x4E00 && page == 2
+//	width*height > 1024 || id == 'A'
+//
+// Expressions use the lowercase Char field names, integer and char literals
+// and the C operators ! * / % + - < <= > >= == != && ||. Division by zero
+// yields 0. A char matches when the expression is not 0.
+func CompileCharFilter(expr string) (CharFilter, error) {
+	p := &filterParser{src: expr}
+	if err := p.tokenize(); err != nil {
+		return nil, fmt.Errorf("Error parsing filter %q: %v", expr, err)
+	}
+
+	node, err := p.parseLevel(0)
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("Unexpected %q", p.peek())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing filter %q: %v", expr, err)
+	}
+
+	return func(c *Char) bool { return node(c) != 0 }, nil
+}
+
+// FilterChars returns copies of the chars matching the filter expression,
+// see CompileCharFilter for the syntax
+func (f *Font) FilterChars(expr string) ([]Char, error) {
+	filter, err := CompileCharFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	var chars []Char
+	for i := range f.Chars {
+		if filter(&f.Chars[i]) {
+			chars = append(chars, f.Chars[i])
+		}
+	}
+	return chars, nil
+}
@@ -0,0 +1,61 @@
+package bmfont
+
+import "testing"
+
+func TestCompileCharFilter(t *testing.T) {
+	c := &Char{Id: 'A', Width: 6, Height: 8, Xoffset: -1, Page: 2}
+	for _, tc := range []struct {
+		expr string
+		want bool
+	}{
+		{"1 + 2 * 3 == 7", true},
+		{"(1 + 2) * 3 == 9", true},
+		{"7 - 2 - 1 == 4", true},
+		{"0 == 1 < 2", false}, // 0 == (1 < 2), relational binds tighter
+		{"1 < 2 == 1", true},
+		{"1 || 0 && 0", true},
+		{"(1 || 0) && 0", false},
+		{"!0", true},
+		{"!(id == 'A')", false},
+		{"!!page", true},
+		{"-xoffset == 1", true},
+		{"- -1 == 1", true},
+		{"-page * 2 == -4", true},
+		{"id == 'A'", true},
+		{"id == 0x41", true},
+		{"'\u4e00' == 0x4E00", true},
+		{"10 / 0 == 0", true},
+		{"10 % 0 == 0", true},
+		{"10 % 4 == 2", true},
+		{"WIDTH*height > 40 && page >= 2", true},
+		{"width*height > 48", false},
+	} {
+		filter, err := CompileCharFilter(tc.expr)
+		if err != nil {
+			t.Errorf("%v: %v", tc.expr, err)
+			continue
+		}
+		if got := filter(c); got != tc.want {
+			t.Errorf("%v: %v, want %v", tc.expr, got, tc.want)
+		}
+	}
+}
+
+func TestCompileCharFilterErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"id ==",
+		"(id == 1",
+		"id == 1)",
+		"foo > 1",
+		"id == 'A",
+		"id == 'AB'",
+		"id == 0xZZ",
+		"1 2",
+		"id # 1",
+	} {
+		if _, err := CompileCharFilter(expr); err == nil {
+			t.Errorf("%q: expected an error", expr)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package bmfont
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Fonts exercising what formats tend to disagree on: signs of offsets and
+// kerning, the order of padding and spacing, channel bytes and extension
+// blocks
+func formatFixtures() map[string]*Font {
+	return map[string]*Font{
+		"empty": {},
+		"signs": {
+			Info:   &Info{FontSize: -32, BitField: INFO_BITFIELD_UNICODE, StretchH: 100, Aa: 1, FontName: "Signs"},
+			Common: &Common{LineHeight: 38, Base: 30, ScaleW: 256, ScaleH: 256, Pages: 1},
+			Pages:  []string{"signs_0.png"},
+			Chars: []Char{
+				{Id: 'j', X: 1, Y: 2, Width: 7, Height: 20, Xoffset: -3, Yoffset: -1, Xadvance: 6, Chnl: CHNL_ALL},
+				{Id: 0x10348, X: 9, Y: 2, Width: 20, Height: 20, Xoffset: 1, Yoffset: 8, Xadvance: -2, Chnl: CHNL_ALL},
+			},
+			KerningPairs: []KerningPair{
+				{First: 'j', Second: 0x10348, Amount: uint16(0xffff - 4)}, // -5
+				{First: 0x10348, Second: 'j', Amount: 0x7fff},
+			},
+		},
+		"padding": {
+			Info: &Info{
+				FontSize: 12, CharSet: CHARSET_RUSSIAN, StretchH: 100,
+				PaddingUp: 1, PaddingRight: 2, PaddingDown: 3, PaddingLeft: 4,
+				SpacingHoriz: 5, SpacingVert: 6, Outline: 7, FontName: "Padding",
+			},
+			Common: &Common{LineHeight: 14, Base: 11, ScaleW: 128, ScaleH: 64, Pages: 2,
+				AlphaChnl: 1, RedChnl: 2, GreenChnl: 3, BlueChnl: 4},
+			Pages: []string{"padding_0.png", "padding_1.png"},
+			Chars: []Char{{Id: 0x416, Width: 9, Height: 9, Xadvance: 10, Page: 1, Chnl: CHNL_ALL}},
+		},
+		"packed": func() *Font {
+			f := packedChannelsFont()
+			f.Info = &Info{FontSize: 16, BitField: INFO_BITFIELD_UNICODE, StretchH: 100}
+			f.Animations = map[uint32][]AnimationFrame{
+				'a': {{X: 8, Width: 8, Height: 8, Duration: 80}, {X: 16, Width: 8, Height: 8, Page: 1, Duration: 120}},
+			}
+			return f
+		}(),
+	}
+}
+
+// Empty and nil slices and maps are the same font, formats do not keep the
+// difference
+func normalizeFont(f *Font) *Font {
+	out := *f
+	out.Journal = nil
+	if len(out.Pages) == 0 {
+		out.Pages = nil
+	}
+	if len(out.Chars) == 0 {
+		out.Chars = nil
+	}
+	if len(out.KerningPairs) == 0 {
+		out.KerningPairs = nil
+	}
+	if len(out.PageChnls) == 0 {
+		out.PageChnls = nil
+	}
+	if len(out.Animations) == 0 {
+		out.Animations = nil
+	}
+	return &out
+}
+
+func decodeBoth(t *testing.T, f *Font) (fromBinary, fromJSON *Font) {
+	t.Helper()
+	decoded := make(map[Format]*Font)
+	for _, format := range []Format{FORMAT_BINARY, FORMAT_JSON} {
+		b, err := f.Encode(format)
+		if err != nil {
+			t.Fatalf("encode %v: %v", format, err)
+		}
+		decoded[format] = NewFont()
+		if err := decoded[format].Decode(b, format); err != nil {
+			t.Fatalf("decode %v: %v", format, err)
+		}
+	}
+	return normalizeFont(decoded[FORMAT_BINARY]), normalizeFont(decoded[FORMAT_JSON])
+}
+
+func TestFormatsDecodeIdentically(t *testing.T) {
+	for name, f := range formatFixtures() {
+		t.Run(name, func(t *testing.T) {
+			fromBinary, fromJSON := decodeBoth(t, f)
+			if !reflect.DeepEqual(fromBinary, fromJSON) {
+				t.Errorf("binary and json differ\nbinary %+v\njson   %+v", fromBinary, fromJSON)
+			}
+			if want := normalizeFont(f); !reflect.DeepEqual(fromBinary, want) {
+				t.Errorf("round trip differs\ngot  %+v\nwant %+v", fromBinary, want)
+			}
+		})
+	}
+}
+
+// Any binary descriptor the parser accepts and can write back must survive
+// the trip through JSON unchanged
+func FuzzBinaryAndJSONAgree(fz *testing.F) {
+	for _, f := range formatFixtures() {
+		b, err := f.Encode(FORMAT_BINARY)
+		if err != nil {
+			fz.Fatal(err)
+		}
+		fz.Add(b)
+	}
+
+	fz.Fuzz(func(t *testing.T, b []byte) {
+		f, err := NewFontFromBuf(b)
+		if err != nil {
+			return
+		}
+		// Font names with bytes the charset leaves undefined decode to
+		// replacement chars and cannot be written back
+		if _, err := f.ToBuffer(); err != nil {
+			return
+		}
+		// Fonts parsed from binary have no distance field, the one thing
+		// binary cannot store. JSON names only the defined flags, so reserved
+		// bits are dropped and everything else must be kept by both formats.
+		if f.Info != nil {
+			f.Info.BitField &= INFO_BITFIELD_SMOOTH | INFO_BITFIELD_UNICODE | INFO_BITFIELD_ITALIC |
+				INFO_BITFIELD_BOLD | INFO_BITFIELD_FIXED_HEIGHT
+		}
+		if f.Common != nil {
+			f.Common.BitField &= COMMON_BITFIELD_PACKED
+		}
+		fromBinary, fromJSON := decodeBoth(t, f)
+		if !reflect.DeepEqual(fromBinary, fromJSON) {
+			t.Errorf("binary and json differ\nbinary %+v %+v %+v\njson   %+v %+v %+v",
+				fromBinary, fromBinary.Info, fromBinary.Common, fromJSON, fromJSON.Info, fromJSON.Common)
+		}
+	})
+}
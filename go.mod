@@ -0,0 +1,5 @@
+module github.com/mogaika/bmfont
+
+go 1.23.0
+
+require golang.org/x/text v0.28.0
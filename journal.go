@@ -0,0 +1,328 @@
+package bmfont
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+)
+
+type OpKind int
+
+const (
+	OP_ADD_CHAR OpKind = iota
+	OP_REMOVE_CHAR
+	OP_SET_CHAR
+	OP_SET_KERNING
+	OP_SET_COMMON
+	OP_SET_PAGE
+)
+
+func (kind OpKind) String() string {
+	switch kind {
+	case OP_ADD_CHAR:
+		return "add_char"
+	case OP_REMOVE_CHAR:
+		return "remove_char"
+	case OP_SET_CHAR:
+		return "set_char"
+	case OP_SET_KERNING:
+		return "set_kerning"
+	case OP_SET_COMMON:
+		return "set_common"
+	case OP_SET_PAGE:
+		return "set_page"
+	default:
+		return fmt.Sprintf("OpKind(%d)", int(kind))
+	}
+}
+
+func (kind OpKind) MarshalText() ([]byte, error) {
+	return []byte(kind.String()), nil
+}
+
+// Operation is a reversible change of a font. Only the fields of its kind
+// are set.
+type Operation struct {
+	Seq  int    `json:"seq"` // Unique within the journal, increasing
+	Kind OpKind `json:"kind"`
+
+	// Position of the char, kerning pair or page in its slice
+	Index int `json:"index"`
+
+	// OP_ADD_CHAR, OP_REMOVE_CHAR and OP_SET_CHAR
+	CharBefore *Char `json:"charBefore,omitempty"`
+	CharAfter  *Char `json:"charAfter,omitempty"`
+
+	// OP_SET_KERNING, nil when the pair does not exist
+	PairBefore *KerningPair `json:"pairBefore,omitempty"`
+	PairAfter  *KerningPair `json:"pairAfter,omitempty"`
+
+	// OP_SET_COMMON
+	CommonBefore *Common `json:"commonBefore,omitempty"`
+	CommonAfter  *Common `json:"commonAfter,omitempty"`
+
+	// OP_SET_PAGE
+	PageBefore string `json:"pageBefore,omitempty"`
+	PageAfter  string `json:"pageAfter,omitempty"`
+}
+
+const (
+	JOURNAL_ACTION_DO   = "do"
+	JOURNAL_ACTION_UNDO = "undo"
+	JOURNAL_ACTION_REDO = "redo"
+)
+
+type JournalEntry struct {
+	Action string `json:"action"`
+	Operation
+}
+
+// Journal records changes made through the Font editing methods, and by the
+// fixes of Sanitize, AuditAdvances, SynthesizeWhitespace, ImportCharsCSV and
+// ReconcilePages, so they can be undone and redone. Set Font.Journal to a new
+// Journal to start recording, fonts without one are edited without any
+// bookkeeping. Methods reading a journal treat nil as empty. Parsing into a
+// font keeps its journal but nothing before the parse can be undone.
+type Journal struct {
+	done   []Operation
+	undone []Operation
+	log    []JournalEntry
+	seq    int
+}
+
+func (j *Journal) CanUndo() bool { return j != nil && len(j.done) != 0 }
+func (j *Journal) CanRedo() bool { return j != nil && len(j.undone) != 0 }
+
+// Head returns the Seq of the last operation in effect, 0 when every
+// operation was undone. Editors compare it with the value at save time to
+// tell whether there are unsaved changes.
+func (j *Journal) Head() int {
+	if j == nil || len(j.done) == 0 {
+		return 0
+	}
+	return j.done[len(j.done)-1].Seq
+}
+
+// Log returns every operation done, undone and redone in order
+func (j *Journal) Log() []JournalEntry {
+	if j == nil {
+		return nil
+	}
+	return slices.Clone(j.log)
+}
+
+// WriteLog writes the log as JSON, one entry per line
+func (j *Journal) WriteLog(w io.Writer) error {
+	if j == nil {
+		return nil
+	}
+	enc := json.NewEncoder(w)
+	for _, entry := range j.log {
+		if err := enc.Encode(&entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Forgets the operations to undo and redo, their indexes point into content
+// that was replaced. The log and the sequence are kept.
+func (j *Journal) reset() {
+	if j != nil {
+		j.done = nil
+		j.undone = nil
+	}
+}
+
+func (f *Font) record(op Operation) {
+	if f.Journal == nil {
+		return
+	}
+	j := f.Journal
+	j.seq++
+	op.Seq = j.seq
+	j.done = append(j.done, op)
+	j.undone = nil
+	j.log = append(j.log, JournalEntry{JOURNAL_ACTION_DO, op})
+}
+
+func (f *Font) apply(op *Operation, reverse bool) {
+	switch op.Kind {
+	case OP_ADD_CHAR, OP_REMOVE_CHAR:
+		if (op.Kind == OP_ADD_CHAR) != reverse {
+			c := op.CharAfter
+			if c == nil {
+				c = op.CharBefore
+			}
+			f.Chars = slices.Insert(f.Chars, op.Index, *c)
+		} else {
+			f.Chars = slices.Delete(f.Chars, op.Index, op.Index+1)
+		}
+	case OP_SET_CHAR:
+		if reverse {
+			f.Chars[op.Index] = *op.CharBefore
+		} else {
+			f.Chars[op.Index] = *op.CharAfter
+		}
+	case OP_SET_KERNING:
+		from, to := op.PairBefore, op.PairAfter
+		if reverse {
+			from, to = to, from
+		}
+		switch {
+		case from == nil:
+			f.KerningPairs = slices.Insert(f.KerningPairs, op.Index, *to)
+		case to == nil:
+			f.KerningPairs = slices.Delete(f.KerningPairs, op.Index, op.Index+1)
+		default:
+			f.KerningPairs[op.Index] = *to
+		}
+	case OP_SET_COMMON:
+		c := op.CommonAfter
+		if reverse {
+			c = op.CommonBefore
+		}
+		f.Common = nil
+		if c != nil {
+			common := *c
+			f.Common = &common
+		}
+	case OP_SET_PAGE:
+		if reverse {
+			f.Pages[op.Index] = op.PageBefore
+		} else {
+			f.Pages[op.Index] = op.PageAfter
+		}
+	}
+}
+
+// Undo reverts the last operation recorded in the journal
+func (f *Font) Undo() bool {
+	j := f.Journal
+	if j == nil || len(j.done) == 0 {
+		return false
+	}
+	op := j.done[len(j.done)-1]
+	j.done = j.done[:len(j.done)-1]
+	f.apply(&op, true)
+	j.undone = append(j.undone, op)
+	j.log = append(j.log, JournalEntry{JOURNAL_ACTION_UNDO, op})
+	return true
+}
+
+// Redo applies the last undone operation again
+func (f *Font) Redo() bool {
+	j := f.Journal
+	if j == nil || len(j.undone) == 0 {
+		return false
+	}
+	op := j.undone[len(j.undone)-1]
+	j.undone = j.undone[:len(j.undone)-1]
+	f.apply(&op, false)
+	j.done = append(j.done, op)
+	j.log = append(j.log, JournalEntry{JOURNAL_ACTION_REDO, op})
+	return true
+}
+
+func (f *Font) charIndex(id uint32) int {
+	return slices.IndexFunc(f.Chars, func(c Char) bool { return c.Id == id })
+}
+
+// AddChar appends a char, failing when a char with the same id exists
+func (f *Font) AddChar(c Char) error {
+	if f.charIndex(c.Id) != -1 {
+		return fmt.Errorf("Char %v already exists", c.Id)
+	}
+	op := Operation{Kind: OP_ADD_CHAR, Index: len(f.Chars), CharAfter: &c}
+	f.apply(&op, false)
+	f.record(op)
+	return nil
+}
+
+// RemoveChar removes the char with the given id, kerning pairs are kept
+func (f *Font) RemoveChar(id uint32) bool {
+	i := f.charIndex(id)
+	if i == -1 {
+		return false
+	}
+	c := f.Chars[i]
+	op := Operation{Kind: OP_REMOVE_CHAR, Index: i, CharBefore: &c}
+	f.apply(&op, false)
+	f.record(op)
+	return true
+}
+
+// SetChar replaces the metrics of the char with the same id
+func (f *Font) SetChar(c Char) error {
+	i := f.charIndex(c.Id)
+	if i == -1 {
+		return fmt.Errorf("Char %v not found", c.Id)
+	}
+	f.setCharAt(i, c)
+	return nil
+}
+
+// Replaces the char at index i, for callers walking Chars that may hold
+// duplicate ids
+func (f *Font) setCharAt(i int, c Char) {
+	before := f.Chars[i]
+	if before == c {
+		return
+	}
+	op := Operation{Kind: OP_SET_CHAR, Index: i, CharBefore: &before, CharAfter: &c}
+	f.apply(&op, false)
+	f.record(op)
+}
+
+// SetKerning sets the kerning amount of a pair, 0 removes the pair
+func (f *Font) SetKerning(first, second uint32, amount int16) {
+	i := slices.IndexFunc(f.KerningPairs, func(kp KerningPair) bool {
+		return kp.First == first && kp.Second == second
+	})
+	op := Operation{Kind: OP_SET_KERNING, Index: i}
+	if i == -1 {
+		op.Index = len(f.KerningPairs)
+	} else {
+		before := f.KerningPairs[i]
+		if int16(before.Amount) == amount {
+			return
+		}
+		op.PairBefore = &before
+	}
+	if amount != 0 {
+		op.PairAfter = &KerningPair{First: first, Second: second, Amount: uint16(amount)}
+	} else if op.PairBefore == nil {
+		return
+	}
+	f.apply(&op, false)
+	f.record(op)
+}
+
+// SetCommon replaces the common block with a copy of c
+func (f *Font) SetCommon(c Common) {
+	op := Operation{Kind: OP_SET_COMMON, CommonAfter: &c}
+	if f.Common != nil {
+		if *f.Common == c {
+			return
+		}
+		before := *f.Common
+		op.CommonBefore = &before
+	}
+	f.apply(&op, false)
+	f.record(op)
+}
+
+// SetPage renames page i
+func (f *Font) SetPage(i int, name string) error {
+	if i < 0 || i >= len(f.Pages) {
+		return fmt.Errorf("Page %v out of range, have %v pages", i, len(f.Pages))
+	}
+	if f.Pages[i] == name {
+		return nil
+	}
+	op := Operation{Kind: OP_SET_PAGE, Index: i, PageBefore: f.Pages[i], PageAfter: name}
+	f.apply(&op, false)
+	f.record(op)
+	return nil
+}
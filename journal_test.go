@@ -0,0 +1,115 @@
+package bmfont
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func journalFont() *Font {
+	return &Font{
+		Info:   &Info{FontSize: 10},
+		Common: &Common{LineHeight: 10, Base: 8},
+		Pages:  []string{"Font_0.png"},
+		Chars: []Char{
+			{Id: 'A', Width: 6, Height: 8, Xoffset: 1000, Xadvance: 7},
+			{Id: 'B', Width: 6, Height: 8, Xadvance: 30},
+		},
+		Journal: &Journal{},
+	}
+}
+
+func TestJournalRecordsPackageFixes(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		mutate func(t *testing.T, f *Font)
+	}{
+		{"Sanitize", func(t *testing.T, f *Font) {
+			f.Sanitize(&SanitizeOptions{Clamp: true})
+		}},
+		{"AuditAdvances", func(t *testing.T, f *Font) {
+			f.AuditAdvances(&AdvanceAuditOptions{Fix: true})
+		}},
+		{"SynthesizeWhitespace", func(t *testing.T, f *Font) {
+			f.SynthesizeWhitespace(nil)
+		}},
+		{"ImportCharsCSV", func(t *testing.T, f *Font) {
+			if err := f.ImportCharsCSV(strings.NewReader("id,x\n65,3\n67,4\n")); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"ReconcilePages", func(t *testing.T, f *Font) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "font_0.png"), nil, 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := f.ReconcilePages(dir, true); err != nil {
+				t.Fatal(err)
+			}
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := journalFont()
+			want := journalFont()
+
+			tc.mutate(t, f)
+			if reflect.DeepEqual(f.Chars, want.Chars) && slices.Equal(f.Pages, want.Pages) {
+				t.Fatal("nothing changed")
+			}
+			if len(f.Journal.Log()) == 0 {
+				t.Fatal("nothing recorded")
+			}
+			changed := slices.Clone(f.Chars)
+
+			for f.Undo() {
+			}
+			if !reflect.DeepEqual(f.Chars, want.Chars) || !slices.Equal(f.Pages, want.Pages) {
+				t.Errorf("undo left chars %+v pages %v", f.Chars, f.Pages)
+			}
+			for f.Redo() {
+			}
+			if !reflect.DeepEqual(f.Chars, changed) {
+				t.Errorf("redo left chars %+v, want %+v", f.Chars, changed)
+			}
+		})
+	}
+}
+
+func TestJournalResetByParsing(t *testing.T) {
+	for _, format := range []Format{FORMAT_BINARY, FORMAT_JSON} {
+		parsed := journalFont()
+		parsed.Chars = parsed.Chars[:1]
+		b, err := parsed.Encode(format)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		f := journalFont()
+		f.Chars = append(f.Chars, Char{Id: 'C'})
+		journal := f.Journal
+		if err := f.AddChar(Char{Id: 'D'}); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.Decode(b, format); err != nil {
+			t.Fatal(err)
+		}
+		if f.Journal != journal {
+			t.Errorf("%v: parsing replaced the journal", format)
+		}
+		if f.Undo() || f.Redo() || len(f.Chars) != 1 {
+			t.Errorf("%v: history survived parsing, chars %+v", format, f.Chars)
+		}
+	}
+}
+
+func TestEditSessionWithoutJournal(t *testing.T) {
+	f := journalFont()
+	s := NewEditSession(f, nil, "", FORMAT_JSON)
+	f.Journal = nil
+	if s.CanUndo() || s.CanRedo() || s.Dirty() || s.Undo() {
+		t.Errorf("session without a journal reports history")
+	}
+}
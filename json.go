@@ -0,0 +1,316 @@
+package bmfont
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Distance field metadata written by msdf-bmfont-xml and similar tools.
+// The binary format has no place for it, so it only survives JSON round-trips.
+type DistanceField struct {
+	FieldType     string `json:"fieldType"`     // "sdf", "psdf", "msdf" or "mtsdf"
+	DistanceRange int    `json:"distanceRange"` // Distance range in pixels
+}
+
+type jsonInfo struct {
+	Face        string          `json:"face"`
+	Size        int16           `json:"size"`
+	Bold        uint8           `json:"bold"`
+	Italic      uint8           `json:"italic"`
+	Charset     json.RawMessage `json:"charset,omitempty"`
+	Unicode     uint8           `json:"unicode"`
+	StretchH    uint16          `json:"stretchH"`
+	Smooth      uint8           `json:"smooth"`
+	Aa          uint8           `json:"aa"`
+	Padding     [4]uint8        `json:"padding"`
+	Spacing     [2]uint8        `json:"spacing"`
+	Outline     uint8           `json:"outline"`
+	FixedHeight uint8           `json:"fixedHeight,omitempty"`
+}
+
+type jsonCommon struct {
+	LineHeight uint16 `json:"lineHeight"`
+	Base       uint16 `json:"base"`
+	ScaleW     uint16 `json:"scaleW"`
+	ScaleH     uint16 `json:"scaleH"`
+	Pages      uint16 `json:"pages"`
+	Packed     uint8  `json:"packed"`
+	AlphaChnl  uint8  `json:"alphaChnl"`
+	RedChnl    uint8  `json:"redChnl"`
+	GreenChnl  uint8  `json:"greenChnl"`
+	BlueChnl   uint8  `json:"blueChnl"`
+}
+
+type jsonChar struct {
+	Id       uint32  `json:"id"`
+	Char     string  `json:"char,omitempty"`
+	X        uint16  `json:"x"`
+	Y        uint16  `json:"y"`
+	Width    uint16  `json:"width"`
+	Height   uint16  `json:"height"`
+	Xoffset  float64 `json:"xoffset"`
+	Yoffset  float64 `json:"yoffset"`
+	Xadvance float64 `json:"xadvance"`
+	Page     uint8   `json:"page"`
+	Chnl     uint8   `json:"chnl"`
+}
+
+type jsonKerning struct {
+	First  uint32  `json:"first"`
+	Second uint32  `json:"second"`
+	Amount float64 `json:"amount"` // Some exporters write floats
+}
+
+type jsonAnimation struct {
+	Id     uint32           `json:"id"`
+	Frames []AnimationFrame `json:"frames"`
+}
+
+type jsonFont struct {
+	Pages         []string        `json:"pages"`
+	Chars         []jsonChar      `json:"chars"`
+	Info          *jsonInfo       `json:"info,omitempty"`
+	Common        *jsonCommon     `json:"common,omitempty"`
+	DistanceField *DistanceField  `json:"distanceField,omitempty"`
+	Kernings      []jsonKerning   `json:"kernings"`
+	PageChnls     []int           `json:"pageChnls,omitempty"`  // Extension, see Font.PageChnls
+	Animations    []jsonAnimation `json:"animations,omitempty"` // Extension, see Font.Animations
+}
+
+func bitFlag(bitField, flag uint8) uint8 {
+	if bitField&flag != 0 {
+		return 1
+	}
+	return 0
+}
+
+func flagBit(v, flag uint8) uint8 {
+	if v != 0 {
+		return flag
+	}
+	return 0
+}
+
+func (f *Font) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.toJSON(false))
+}
+
+// Compact output leaves out the "char" helper field, which only exists for
+// humans reading the file
+func (f *Font) toJSON(compact bool) *jsonFont {
+	jf := jsonFont{
+		Pages:         f.Pages,
+		Chars:         make([]jsonChar, len(f.Chars)),
+		DistanceField: f.DistanceField,
+		Kernings:      make([]jsonKerning, len(f.KerningPairs)),
+	}
+	if jf.Pages == nil {
+		jf.Pages = []string{}
+	}
+
+	if i := f.Info; i != nil {
+		charset, _ := json.Marshal(i.CharSet)
+		jf.Info = &jsonInfo{
+			Face:        i.FontName,
+			Size:        i.FontSize,
+			Bold:        bitFlag(i.BitField, INFO_BITFIELD_BOLD),
+			Italic:      bitFlag(i.BitField, INFO_BITFIELD_ITALIC),
+			Charset:     charset,
+			Unicode:     bitFlag(i.BitField, INFO_BITFIELD_UNICODE),
+			StretchH:    i.StretchH,
+			Smooth:      bitFlag(i.BitField, INFO_BITFIELD_SMOOTH),
+			Aa:          i.Aa,
+			Padding:     [4]uint8{i.PaddingUp, i.PaddingRight, i.PaddingDown, i.PaddingLeft},
+			Spacing:     [2]uint8{i.SpacingHoriz, i.SpacingVert},
+			Outline:     i.Outline,
+			FixedHeight: bitFlag(i.BitField, INFO_BITFIELD_FIXED_HEIGHT),
+		}
+	}
+
+	if c := f.Common; c != nil {
+		jf.Common = &jsonCommon{
+			LineHeight: c.LineHeight,
+			Base:       c.Base,
+			ScaleW:     c.ScaleW,
+			ScaleH:     c.ScaleH,
+			Pages:      c.Pages,
+			Packed:     bitFlag(c.BitField, COMMON_BITFIELD_PACKED),
+			AlphaChnl:  c.AlphaChnl,
+			RedChnl:    c.RedChnl,
+			GreenChnl:  c.GreenChnl,
+			BlueChnl:   c.BlueChnl,
+		}
+	}
+
+	for i, c := range f.Chars {
+		jf.Chars[i] = jsonChar{
+			Id:       c.Id,
+			Char:     string(rune(c.Id)),
+			X:        c.X,
+			Y:        c.Y,
+			Width:    c.Width,
+			Height:   c.Height,
+			Xoffset:  float64(c.Xoffset),
+			Yoffset:  float64(c.Yoffset),
+			Xadvance: float64(c.Xadvance),
+			Page:     c.Page,
+			Chnl:     c.Chnl,
+		}
+	}
+
+	for i, kp := range f.KerningPairs {
+		jf.Kernings[i] = jsonKerning{
+			First:  kp.First,
+			Second: kp.Second,
+			Amount: float64(int16(kp.Amount)),
+		}
+	}
+
+	for _, chnl := range f.PageChnls {
+		jf.PageChnls = append(jf.PageChnls, int(chnl))
+	}
+
+	for id, frames := range f.Animations {
+		jf.Animations = append(jf.Animations, jsonAnimation{Id: id, Frames: frames})
+	}
+	sort.Slice(jf.Animations, func(i, j int) bool { return jf.Animations[i].Id < jf.Animations[j].Id })
+
+	if compact {
+		for i := range jf.Chars {
+			jf.Chars[i].Char = ""
+		}
+	}
+
+	return &jf
+}
+
+func (f *Font) UnmarshalJSON(b []byte) error {
+	return f.FromJSONWithOptions(b, nil)
+}
+
+// FromJSONWithOptions parses a JSON descriptor. Float offsets, advances and
+// kerning amounts are rounded as set in opts and reported to opts.Report.
+func (f *Font) FromJSONWithOptions(b []byte, opts *Options) error {
+	return countParse(len(b), f.fromJSON(b, opts))
+}
+
+func (f *Font) fromJSON(b []byte, opts *Options) error {
+	b, err := textDescriptor(b)
+	if err != nil {
+		return fmt.Errorf("Error decoding json text: %v", err)
+	}
+
+	var jf jsonFont
+	if err := json.Unmarshal(b, &jf); err != nil {
+		return fmt.Errorf("Error parsing json: %v", err)
+	}
+
+	*f = Font{
+		Pages:         jf.Pages,
+		Chars:         make([]Char, len(jf.Chars)),
+		KerningPairs:  make([]KerningPair, len(jf.Kernings)),
+		DistanceField: jf.DistanceField,
+		Journal:       f.Journal,
+	}
+	f.Journal.reset()
+
+	if ji := jf.Info; ji != nil {
+		f.Info = &Info{
+			FontSize: ji.Size,
+			BitField: flagBit(ji.Bold, INFO_BITFIELD_BOLD) |
+				flagBit(ji.Italic, INFO_BITFIELD_ITALIC) |
+				flagBit(ji.Unicode, INFO_BITFIELD_UNICODE) |
+				flagBit(ji.Smooth, INFO_BITFIELD_SMOOTH) |
+				flagBit(ji.FixedHeight, INFO_BITFIELD_FIXED_HEIGHT),
+			StretchH:     ji.StretchH,
+			Aa:           ji.Aa,
+			PaddingUp:    ji.Padding[0],
+			PaddingRight: ji.Padding[1],
+			PaddingDown:  ji.Padding[2],
+			PaddingLeft:  ji.Padding[3],
+			SpacingHoriz: ji.Spacing[0],
+			SpacingVert:  ji.Spacing[1],
+			Outline:      ji.Outline,
+			FontName:     ji.Face,
+		}
+		// Web tools write the charset as a list of characters, only numeric
+		// OEM charsets map onto the descriptor
+		var charset uint8
+		if json.Unmarshal(ji.Charset, &charset) == nil {
+			f.Info.CharSet = charset
+		}
+	}
+
+	if jc := jf.Common; jc != nil {
+		f.Common = &Common{
+			LineHeight: jc.LineHeight,
+			Base:       jc.Base,
+			ScaleW:     jc.ScaleW,
+			ScaleH:     jc.ScaleH,
+			Pages:      jc.Pages,
+			BitField:   flagBit(jc.Packed, COMMON_BITFIELD_PACKED),
+			AlphaChnl:  jc.AlphaChnl,
+			RedChnl:    jc.RedChnl,
+			GreenChnl:  jc.GreenChnl,
+			BlueChnl:   jc.BlueChnl,
+		}
+	}
+
+	for i, jc := range jf.Chars {
+		f.Chars[i] = Char{
+			Id:     jc.Id,
+			X:      jc.X,
+			Y:      jc.Y,
+			Width:  jc.Width,
+			Height: jc.Height,
+			Page:   jc.Page,
+			Chnl:   jc.Chnl,
+		}
+
+		var err error
+		if f.Chars[i].Xoffset, err = opts.roundInt16(fmt.Sprintf("chars[%v].xoffset", i), jc.Xoffset); err != nil {
+			return err
+		}
+		if f.Chars[i].Yoffset, err = opts.roundInt16(fmt.Sprintf("chars[%v].yoffset", i), jc.Yoffset); err != nil {
+			return err
+		}
+		if f.Chars[i].Xadvance, err = opts.roundInt16(fmt.Sprintf("chars[%v].xadvance", i), jc.Xadvance); err != nil {
+			return err
+		}
+	}
+
+	for i, jk := range jf.Kernings {
+		amount, err := opts.roundInt16(fmt.Sprintf("kernings[%v].amount", i), jk.Amount)
+		if err != nil {
+			return err
+		}
+		f.KerningPairs[i] = KerningPair{
+			First:  jk.First,
+			Second: jk.Second,
+			Amount: uint16(amount),
+		}
+	}
+
+	for _, chnl := range jf.PageChnls {
+		f.PageChnls = append(f.PageChnls, uint8(chnl))
+	}
+
+	if len(jf.Animations) != 0 {
+		f.Animations = make(map[uint32][]AnimationFrame, len(jf.Animations))
+		for _, ja := range jf.Animations {
+			f.Animations[ja.Id] = ja.Frames
+		}
+	}
+
+	return nil
+}
+
+func NewFontFromJSON(b []byte) (*Font, error) {
+	return NewFontFromJSONWithOptions(b, nil)
+}
+
+func NewFontFromJSONWithOptions(b []byte, opts *Options) (*Font, error) {
+	f := NewFont()
+	return f, f.FromJSONWithOptions(b, opts)
+}
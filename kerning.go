@@ -0,0 +1,93 @@
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"sort"
+)
+
+// kerningIds returns the sorted ids of all chars that take part in a kerning
+// pair and the index of every id in that list
+func (f *Font) kerningIds() ([]uint32, map[uint32]int) {
+	index := make(map[uint32]int)
+	for _, kp := range f.KerningPairs {
+		index[kp.First] = 0
+		index[kp.Second] = 0
+	}
+
+	ids := make([]uint32, 0, len(index))
+	for id := range index {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for i, id := range ids {
+		index[id] = i
+	}
+	return ids, index
+}
+
+// KerningHeatmap draws the kerning matrix with one cellSize square per pair,
+// first chars along the Y axis and second chars along the X axis in id order.
+// Negative amounts are blue, positive red, stronger for larger amounts, and
+// pairs without kerning are white.
+func (f *Font) KerningHeatmap(cellSize int) *image.RGBA {
+	if cellSize <= 0 {
+		cellSize = 1
+	}
+	ids, index := f.kerningIds()
+	img := image.NewRGBA(image.Rect(0, 0, len(ids)*cellSize, len(ids)*cellSize))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+
+	maxAbs := 0
+	for _, kp := range f.KerningPairs {
+		maxAbs = max(maxAbs, abs(int(int16(kp.Amount))))
+	}
+	if maxAbs == 0 {
+		return img
+	}
+
+	for _, kp := range f.KerningPairs {
+		amount := int(int16(kp.Amount))
+		fade := uint8(255 - abs(amount)*255/maxAbs)
+		c := color.RGBA{255, fade, fade, 255}
+		if amount < 0 {
+			c = color.RGBA{fade, fade, 255, 255}
+		}
+
+		x0, y0 := index[kp.Second]*cellSize, index[kp.First]*cellSize
+		for y := y0; y < y0+cellSize; y++ {
+			for x := x0; x < x0+cellSize; x++ {
+				img.SetRGBA(x, y, c)
+			}
+		}
+	}
+	return img
+}
+
+// KerningHeatmapIds returns the char ids along the axes of KerningHeatmap
+func (f *Font) KerningHeatmapIds() []uint32 {
+	ids, _ := f.kerningIds()
+	return ids
+}
+
+// ExtremeKerningPairs returns up to n pairs with the largest absolute amount,
+// largest first. Bogus values from buggy exporters end up at the top.
+func (f *Font) ExtremeKerningPairs(n int) []KerningPair {
+	pairs := append([]KerningPair(nil), f.KerningPairs...)
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return abs(int(int16(pairs[i].Amount))) > abs(int(int16(pairs[j].Amount)))
+	})
+	if n < len(pairs) {
+		pairs = pairs[:n]
+	}
+	return pairs
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
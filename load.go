@@ -0,0 +1,109 @@
+package bmfont
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+type PageDecoder func(r io.Reader) (image.Image, error)
+
+// Decoders used for page images by extension. Extensions without a decoder
+// fall back to image.Decode, so formats registered with image.RegisterFormat
+// (TGA, DDS, ...) work as well.
+var PageDecoders = map[string]PageDecoder{
+	".png": png.Decode,
+}
+
+type LoadOptions struct {
+	Options
+	Decoders      map[string]PageDecoder // Extra decoders by lowercase extension, take precedence over PageDecoders
+	SkipSizeCheck bool                   // Do not require pages to be Common.ScaleW x Common.ScaleH
+}
+
+func (opts *LoadOptions) decoder(name string) PageDecoder {
+	ext := strings.ToLower(path.Ext(name))
+	if opts != nil {
+		if d, ok := opts.Decoders[ext]; ok {
+			return d
+		}
+	}
+	if d, ok := PageDecoders[ext]; ok {
+		return d
+	}
+	return func(r io.Reader) (image.Image, error) {
+		img, _, err := image.Decode(r)
+		return img, err
+	}
+}
+
+func loadPage(fsys fs.FS, name string, decode PageDecoder) (image.Image, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return decode(file)
+}
+
+// LoadPages decodes the page images of the font, resolving page names relative
+// to dir inside fsys.
+func (f *Font) LoadPages(fsys fs.FS, dir string, opts *LoadOptions) (PageImages, error) {
+	if opts == nil {
+		opts = &LoadOptions{}
+	}
+
+	pages := make(PageImages, len(f.Pages))
+	for i, page := range f.Pages {
+		name := path.Join(dir, normalizePageName(page))
+		img, err := loadPage(fsys, name, opts.decoder(name))
+		if err != nil {
+			return nil, fmt.Errorf("Error loading page %v %q: %v", i, page, err)
+		}
+
+		if !opts.SkipSizeCheck && f.Common != nil {
+			size := img.Bounds().Size()
+			if size.X != int(f.Common.ScaleW) || size.Y != int(f.Common.ScaleH) {
+				return nil, fmt.Errorf("Page %v %q is %vx%v, expected %vx%v",
+					i, page, size.X, size.Y, f.Common.ScaleW, f.Common.ScaleH)
+			}
+		}
+		pages[i] = img
+	}
+	return pages, nil
+}
+
+// LoadFont reads a binary or JSON descriptor from path along with its pages
+func LoadFont(path string, opts *LoadOptions) (*Font, PageImages, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	format, err := DetectFormat(b)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error loading %v: %v", path, err)
+	}
+
+	var parseOpts *Options
+	if opts != nil {
+		parseOpts = &opts.Options
+	}
+
+	f := NewFont()
+	if err := f.DecodeWithOptions(b, format, parseOpts); err != nil {
+		return nil, nil, fmt.Errorf("Error parsing %v: %v", path, err)
+	}
+
+	pages, err := f.LoadPages(os.DirFS(filepath.Dir(path)), ".", opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, pages, nil
+}
@@ -0,0 +1,61 @@
+package bmfont
+
+import (
+	"expvar"
+	"sync/atomic"
+)
+
+// Counters reported to Metrics
+const (
+	METRIC_FONTS_LOADED = "fonts_loaded" // Descriptors parsed successfully
+	METRIC_PARSE_ERRORS = "parse_errors" // Descriptors that failed to parse
+	METRIC_BYTES_PARSED = "bytes_parsed" // Size of all parsed descriptors
+)
+
+// Metrics receives instrumentation counters of the package. Implementations
+// must be safe for concurrent use.
+type Metrics interface {
+	Add(counter string, delta int64)
+}
+
+type metricsHolder struct {
+	m Metrics
+}
+
+var metrics atomic.Value // metricsHolder
+
+// SetMetrics installs m as the receiver of package counters, nil disables
+// instrumentation
+func SetMetrics(m Metrics) {
+	metrics.Store(metricsHolder{m})
+}
+
+func addMetric(counter string, delta int64) {
+	if h, ok := metrics.Load().(metricsHolder); ok && h.m != nil {
+		h.m.Add(counter, delta)
+	}
+}
+
+func countParse(size int, err error) error {
+	if err != nil {
+		addMetric(METRIC_PARSE_ERRORS, 1)
+	} else {
+		addMetric(METRIC_FONTS_LOADED, 1)
+	}
+	addMetric(METRIC_BYTES_PARSED, int64(size))
+	return err
+}
+
+type expvarMetrics struct {
+	vars *expvar.Map
+}
+
+// NewExpvarMetrics publishes the counters as an expvar map under name. Like
+// expvar.NewMap it panics if name is already published.
+func NewExpvarMetrics(name string) Metrics {
+	return &expvarMetrics{vars: expvar.NewMap(name)}
+}
+
+func (em *expvarMetrics) Add(counter string, delta int64) {
+	em.vars.Add(counter, delta)
+}
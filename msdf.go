@@ -0,0 +1,188 @@
+package bmfont
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+)
+
+func glyphMask(img image.Image, c *Char, chnl uint8) []bool {
+	x0, y0 := img.Bounds().Min.X+int(c.X), img.Bounds().Min.Y+int(c.Y)
+	w, h := int(c.Width), int(c.Height)
+
+	// Encoded glyph & outline keep the glyph in the upper half of the
+	// range, so the same threshold works for plain glyph channels too
+	mask := make([]bool, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			mask[y*w+x] = sdfValue(img, x0+x, y0+y, chnl, "") >= 128
+		}
+	}
+	return mask
+}
+
+// Squared distance of every pixel to the nearest pixel where mask equals
+// target, using the 8-point sequential euclidean distance transform
+func distanceTransform(mask []bool, w, h int, target bool) []float64 {
+	const far = 1 << 20
+	type offset struct{ dx, dy int }
+	grid := make([]offset, w*h)
+	for i, m := range mask {
+		if m == target {
+			grid[i] = offset{0, 0}
+		} else {
+			grid[i] = offset{far, far}
+		}
+	}
+
+	dist2 := func(o offset) int { return o.dx*o.dx + o.dy*o.dy }
+	compare := func(x, y, ox, oy int) {
+		nx, ny := x+ox, y+oy
+		if nx < 0 || ny < 0 || nx >= w || ny >= h {
+			return
+		}
+		other := grid[ny*w+nx]
+		other.dx += ox
+		other.dy += oy
+		if dist2(other) < dist2(grid[y*w+x]) {
+			grid[y*w+x] = other
+		}
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			compare(x, y, -1, 0)
+			compare(x, y, 0, -1)
+			compare(x, y, -1, -1)
+			compare(x, y, 1, -1)
+		}
+		for x := w - 1; x >= 0; x-- {
+			compare(x, y, 1, 0)
+		}
+	}
+	for y := h - 1; y >= 0; y-- {
+		for x := w - 1; x >= 0; x-- {
+			compare(x, y, 1, 0)
+			compare(x, y, 0, 1)
+			compare(x, y, -1, 1)
+			compare(x, y, 1, 1)
+		}
+		for x := 0; x < w; x++ {
+			compare(x, y, -1, 0)
+		}
+	}
+
+	result := make([]float64, w*h)
+	for i, o := range grid {
+		result[i] = float64(dist2(o))
+	}
+	return result
+}
+
+// SynthesizePseudoMSDF converts a high resolution bitmap font, packed or not,
+// into a font with a signed distance field repeated in the red, green and blue
+// channels. Shaders written for msdf fonts take the median of the three, which
+// for equal channels is the plain distance field, so old assets can be used
+// with modern shaders.
+//
+// Every channel of a packed source page gets its own output page. Pages of the
+// returned font are left empty, name them with PageImages.Names or
+// PageImages.WriteFiles. distanceRange is in pixels and should not exceed
+// twice the glyph padding. Animations are dropped, their frames point into
+// the source atlas.
+//
+// Experimental: there are no true multi-channel corners, sharp corners come
+// out as rounded as in any single channel distance field.
+func (f *Font) SynthesizePseudoMSDF(pages PageImages, distanceRange int) (*Font, PageImages, error) {
+	if distanceRange <= 0 {
+		return nil, nil, fmt.Errorf("Invalid distance range %v", distanceRange)
+	}
+	if f.Common == nil {
+		return nil, nil, fmt.Errorf("Font has no common block")
+	}
+
+	out := *f
+	out.Chars = append([]Char(nil), f.Chars...)
+	out.KerningPairs = append([]KerningPair(nil), f.KerningPairs...)
+	if f.Info != nil {
+		info := *f.Info
+		out.Info = &info
+	}
+	out.Pages = nil
+	out.PageChnls = nil
+	out.Animations = nil
+	out.Journal = nil
+	out.DistanceField = &DistanceField{FieldType: "msdf", DistanceRange: distanceRange}
+	common := *f.Common
+	common.BitField &^= COMMON_BITFIELD_PACKED
+	common.AlphaChnl, common.RedChnl, common.GreenChnl, common.BlueChnl = 0, 0, 0, 0
+	out.Common = &common
+
+	var outPages PageImages
+	// (source page, channel) to output page
+	pageMap := make(map[[2]int]int)
+
+	for ci := range out.Chars {
+		c := &out.Chars[ci]
+		src := f.Chars[ci]
+		if int(src.Page) >= len(pages) || pages[src.Page] == nil {
+			return nil, nil, fmt.Errorf("Char %v refers to missing page %v", src.Id, src.Page)
+		}
+
+		chnl := f.CharChnl(&src)
+		channelIndex := 0
+		for i, bit := range packedChannels {
+			if chnl == bit {
+				channelIndex = i
+			}
+		}
+
+		key := [2]int{int(src.Page), channelIndex}
+		pageIndex, ok := pageMap[key]
+		if !ok {
+			pageIndex = len(outPages)
+			if pageIndex > math.MaxUint8 {
+				return nil, nil, fmt.Errorf("Too many output pages")
+			}
+			pageMap[key] = pageIndex
+
+			page := image.NewRGBA(image.Rect(0, 0, int(common.ScaleW), int(common.ScaleH)))
+			for i := 0; i < len(page.Pix); i += 4 {
+				page.Pix[i+3] = 255
+			}
+			outPages = append(outPages, page)
+		}
+		c.Page = uint8(pageIndex)
+		c.Chnl = CHNL_ALL
+
+		w, h := int(src.Width), int(src.Height)
+		if w == 0 || h == 0 {
+			continue
+		}
+
+		mask := glyphMask(pages[src.Page], &src, f.glyphChannel(chnl))
+		toInside := distanceTransform(mask, w, h, true)
+		toOutside := distanceTransform(mask, w, h, false)
+
+		dst := outPages[pageIndex].(*image.RGBA)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				i := y*w + x
+				// Positive inside, measured to pixel edges rather than centers
+				var d float64
+				if mask[i] {
+					d = math.Sqrt(toOutside[i]) - 0.5
+				} else {
+					d = 0.5 - math.Sqrt(toInside[i])
+				}
+				v := 0.5 + d/float64(distanceRange)
+				b := uint8(math.Round(math.Max(0, math.Min(1, v)) * 255))
+				dst.SetRGBA(int(src.X)+x, int(src.Y)+y, color.RGBA{b, b, b, 255})
+			}
+		}
+	}
+
+	common.Pages = uint16(len(outPages))
+	return &out, outPages, nil
+}
@@ -0,0 +1,73 @@
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// Opaque page with a white square glyph in RGB, as exported with alpha set
+// to one
+func opaqueGlyphFont() (*Font, PageImages) {
+	page := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			v := uint8(0)
+			if x >= 4 && x < 12 && y >= 4 && y < 12 {
+				v = 255
+			}
+			page.SetNRGBA(x, y, color.NRGBA{v, v, v, 255})
+		}
+	}
+	f := &Font{
+		Info:         &Info{FontSize: 16, FontName: "Opaque"},
+		Common:       &Common{LineHeight: 16, Base: 12, ScaleW: 16, ScaleH: 16, Pages: 1, AlphaChnl: 4},
+		Pages:        []string{"opaque_0.png"},
+		Chars:        []Char{{Id: 'A', Width: 16, Height: 16, Xadvance: 16, Chnl: CHNL_ALL}},
+		KerningPairs: []KerningPair{{First: 'A', Second: 'A', Amount: 1}},
+		Animations:   map[uint32][]AnimationFrame{'A': {{Width: 16, Height: 16, Duration: 100}}},
+		Journal:      &Journal{},
+	}
+	return f, PageImages{page}
+}
+
+func TestSynthesizePseudoMSDFOpaquePage(t *testing.T) {
+	f, pages := opaqueGlyphFont()
+	out, outPages, err := f.SynthesizePseudoMSDF(pages, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &out.Chars[0]
+	page := outPages[c.Page]
+	inside := sdfValue(page, int(c.X)+8, int(c.Y)+8, CHNL_RED, "")
+	outside := sdfValue(page, int(c.X), int(c.Y), CHNL_RED, "")
+	if inside <= 128 || outside >= 128 {
+		t.Errorf("distance inside %v, outside %v", inside, outside)
+	}
+}
+
+func TestSynthesizePseudoMSDFDetachesFont(t *testing.T) {
+	f, pages := opaqueGlyphFont()
+	out, _, err := f.SynthesizePseudoMSDF(pages, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Journal != nil || out.Animations != nil {
+		t.Errorf("output shares journal %v or animations %v", out.Journal, out.Animations)
+	}
+
+	out.Journal = &Journal{}
+	if err := out.AddChar(Char{Id: 'B'}); err != nil {
+		t.Fatal(err)
+	}
+	if f.Undo() {
+		t.Errorf("source font undid an edit of the output font")
+	}
+
+	out.SetKerning('A', 'A', 5)
+	out.Info.FontName = "changed"
+	if f.KerningPairs[0].Amount != 1 || f.Info.FontName != "Opaque" {
+		t.Errorf("output edits changed the source, kerning %+v, info %+v", f.KerningPairs, f.Info)
+	}
+}
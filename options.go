@@ -0,0 +1,73 @@
+package bmfont
+
+import (
+	"fmt"
+	"math"
+
+	"golang.org/x/text/encoding"
+)
+
+type Rounding int
+
+// How non integer metrics written by some exporters are rounded
+const (
+	ROUNDING_HALF_EVEN Rounding = iota
+	ROUNDING_TRUNCATE
+)
+
+func (r Rounding) apply(v float64) float64 {
+	switch r {
+	case ROUNDING_TRUNCATE:
+		return math.Trunc(v)
+	default:
+		return math.RoundToEven(v)
+	}
+}
+
+type Options struct {
+	// Encoding of the font name and page names. When nil it is selected from
+	// the unicode bit and Info.CharSet, see EncodingForCharSet
+	Encoding encoding.Encoding
+
+	// Rounding of float metrics in JSON descriptors
+	Rounding Rounding
+
+	// Report, when set, receives issues that did not stop the parse
+	Report *ParseReport
+}
+
+type RoundedValue struct {
+	Field   string // Where the value was found, e.g. "chars[3].xadvance"
+	Value   float64
+	Rounded int16
+}
+
+type ParseReport struct {
+	Rounded []RoundedValue // Float metrics that lost precision
+}
+
+// MaxRoundingError returns the largest difference between a float metric and
+// the value stored in the font
+func (r *ParseReport) MaxRoundingError() float64 {
+	var maxErr float64
+	for _, rv := range r.Rounded {
+		maxErr = math.Max(maxErr, math.Abs(rv.Value-float64(rv.Rounded)))
+	}
+	return maxErr
+}
+
+func (opts *Options) roundInt16(field string, v float64) (int16, error) {
+	var rounding Rounding
+	if opts != nil {
+		rounding = opts.Rounding
+	}
+
+	rounded := rounding.apply(v)
+	if rounded < math.MinInt16 || rounded > math.MaxInt16 {
+		return 0, fmt.Errorf("Value %v of %v out of range", v, field)
+	}
+	if rounded != v && opts != nil && opts.Report != nil {
+		opts.Report.Rounded = append(opts.Report.Rounded, RoundedValue{Field: field, Value: v, Rounded: int16(rounded)})
+	}
+	return int16(rounded), nil
+}
@@ -0,0 +1,72 @@
+package bmfont
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+const roundingJSON = `{
+	"pages": [],
+	"chars": [
+		{"id": 65, "xoffset": 1, "yoffset": 0, "xadvance": 2.5},
+		{"id": 66, "xoffset": 0, "yoffset": 0, "xadvance": 3.5},
+		{"id": 67, "xoffset": 0, "yoffset": 0, "xadvance": -1.5}
+	],
+	"kernings": [{"first": 65, "second": 66, "amount": -2.7}]
+}`
+
+func TestJSONRounding(t *testing.T) {
+	for _, tc := range []struct {
+		rounding Rounding
+		advances []int16
+		amount   int16
+		maxErr   float64
+	}{
+		{ROUNDING_HALF_EVEN, []int16{2, 4, -2}, -3, 0.5},
+		{ROUNDING_TRUNCATE, []int16{2, 3, -1}, -2, 0.7},
+	} {
+		report := &ParseReport{}
+		f, err := NewFontFromJSONWithOptions([]byte(roundingJSON), &Options{Rounding: tc.rounding, Report: report})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var advances []int16
+		for _, c := range f.Chars {
+			advances = append(advances, c.Xadvance)
+		}
+		if !reflect.DeepEqual(advances, tc.advances) || f.Chars[0].Xoffset != 1 {
+			t.Errorf("rounding %v: advances %v, xoffset %v", tc.rounding, advances, f.Chars[0].Xoffset)
+		}
+		if amount := int16(f.KerningPairs[0].Amount); amount != tc.amount {
+			t.Errorf("rounding %v: kerning %v, want %v", tc.rounding, amount, tc.amount)
+		}
+
+		want := []RoundedValue{
+			{"chars[0].xadvance", 2.5, tc.advances[0]},
+			{"chars[1].xadvance", 3.5, tc.advances[1]},
+			{"chars[2].xadvance", -1.5, tc.advances[2]},
+			{"kernings[0].amount", -2.7, tc.amount},
+		}
+		if !reflect.DeepEqual(report.Rounded, want) {
+			t.Errorf("rounding %v: report %+v, want %+v", tc.rounding, report.Rounded, want)
+		}
+		if maxErr := report.MaxRoundingError(); math.Abs(maxErr-tc.maxErr) > 1e-9 {
+			t.Errorf("rounding %v: max error %v, want %v", tc.rounding, maxErr, tc.maxErr)
+		}
+	}
+}
+
+func TestJSONRoundingOutOfRange(t *testing.T) {
+	for _, v := range []string{"32767.4", "-32768.4"} {
+		if _, err := NewFontFromJSON([]byte(`{"chars": [{"id": 65, "xadvance": ` + v + `}]}`)); err != nil {
+			t.Errorf("%v: %v", v, err)
+		}
+	}
+	for _, v := range []string{"32768", "-32769", "1e9"} {
+		if _, err := NewFontFromJSON([]byte(`{"chars": [{"id": 65, "xadvance": ` + v + `}]}`)); err == nil {
+			t.Errorf("%v: expected an error", v)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package bmfont
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type PageEncoder func(w io.Writer, img image.Image) error
+
+// Encoders used for page images by lowercase extension
+var PageEncoders = map[string]PageEncoder{
+	".png": png.Encode,
+}
+
+// Template used when no page name template is given. Templates can use
+//
+//	{name}     font name
+//	{basename} descriptor file name without extension
+//	{page}     page index, same as {index}
+//	{index}    page index
+//	{ext}      image extension without the dot
+const DEFAULT_PAGE_NAME_TEMPLATE = "{name}_{page}.{ext}"
+
+type PageNameOptions struct {
+	Template   string // DEFAULT_PAGE_NAME_TEMPLATE when empty
+	Name       string // Value of {name}
+	BaseName   string // Value of {basename}
+	Ext        string // Value of {ext}, "png" when empty
+	IndexWidth int    // Zero pad {page} and {index} to this many digits
+}
+
+func (o *PageNameOptions) PageName(page int) string {
+	template, ext := o.Template, o.Ext
+	if template == "" {
+		template = DEFAULT_PAGE_NAME_TEMPLATE
+	}
+	if ext == "" {
+		ext = "png"
+	}
+
+	index := strconv.Itoa(page)
+	if len(index) < o.IndexWidth {
+		index = strings.Repeat("0", o.IndexWidth-len(index)) + index
+	}
+
+	return strings.NewReplacer(
+		"{name}", o.Name,
+		"{basename}", o.BaseName,
+		"{page}", index,
+		"{index}", index,
+		"{ext}", ext,
+	).Replace(template)
+}
+
+func PageName(template string, name string, page int) string {
+	return (&PageNameOptions{Template: template, Name: name}).PageName(page)
+}
+
+// PageImages holds page images in page order, either decoded by LoadPages or
+// produced in memory
+type PageImages []image.Image
+
+func (p PageImages) Names(template string, name string) []string {
+	return p.NamesWithOptions(&PageNameOptions{Template: template, Name: name})
+}
+
+func (p PageImages) NamesWithOptions(opts *PageNameOptions) []string {
+	names := make([]string, len(p))
+	for i := range p {
+		names[i] = opts.PageName(i)
+	}
+	return names
+}
+
+// EncodePage writes page i to w in the format registered for ext in PageEncoders
+func (p PageImages) EncodePage(w io.Writer, i int, ext string) error {
+	if i < 0 || i >= len(p) {
+		return fmt.Errorf("Page %v out of range, have %v pages", i, len(p))
+	}
+	encode, ok := PageEncoders[strings.ToLower(ext)]
+	if !ok {
+		return fmt.Errorf("No page encoder for %q", ext)
+	}
+	return encode(w, p[i])
+}
+
+// WriteFiles stores every page in dir using names generated from template and
+// returns the names, ready to be assigned to Font.Pages
+func (p PageImages) WriteFiles(dir string, template string, name string) ([]string, error) {
+	return p.WriteFilesWithOptions(dir, &PageNameOptions{Template: template, Name: name})
+}
+
+func (p PageImages) WriteFilesWithOptions(dir string, opts *PageNameOptions) ([]string, error) {
+	names := p.NamesWithOptions(opts)
+	for i, pageName := range names {
+		if err := p.writeFile(filepath.Join(dir, filepath.FromSlash(pageName)), i, path.Ext(pageName)); err != nil {
+			return nil, fmt.Errorf("Error writing page %v %q: %v", i, pageName, err)
+		}
+	}
+	return names, nil
+}
+
+func (p PageImages) writeFile(fileName string, i int, ext string) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	if err := p.EncodePage(file, i, ext); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
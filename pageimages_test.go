@@ -0,0 +1,19 @@
+package bmfont
+
+import "testing"
+
+func TestPageName(t *testing.T) {
+	for _, tc := range []struct {
+		opts PageNameOptions
+		want string
+	}{
+		{PageNameOptions{Name: "font"}, "font_1.png"},
+		{PageNameOptions{Name: "font", Ext: "tga"}, "font_1.tga"},
+		{PageNameOptions{Name: "font", Ext: "tga", IndexWidth: 3}, "font_001.tga"},
+		{PageNameOptions{Template: "{basename}/{index}.{ext}", BaseName: "ui"}, "ui/1.png"},
+	} {
+		if got := tc.opts.PageName(1); got != tc.want {
+			t.Errorf("%+v: %q, want %q", tc.opts, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+// Package promadapter exports bmfont instrumentation counters to Prometheus.
+// It is a separate module so the core does not depend on the client.
+package promadapter
+
+import (
+	"github.com/mogaika/bmfont"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type Metrics struct {
+	counters *prometheus.CounterVec
+}
+
+// New registers a <namespace>_bmfont_events_total counter with reg, labeled by
+// the bmfont counter name
+func New(namespace string, reg prometheus.Registerer) (*Metrics, error) {
+	counters := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "bmfont",
+		Name:      "events_total",
+		Help:      "Counters of the bmfont package by kind.",
+	}, []string{"counter"})
+
+	if err := reg.Register(counters); err != nil {
+		return nil, err
+	}
+	return &Metrics{counters: counters}, nil
+}
+
+func (m *Metrics) Add(counter string, delta int64) {
+	if delta > 0 {
+		m.counters.WithLabelValues(counter).Add(float64(delta))
+	}
+}
+
+var _ bmfont.Metrics = (*Metrics)(nil)
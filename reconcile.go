@@ -0,0 +1,121 @@
+package bmfont
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Extensions of files considered to be page images during reconciliation
+var PageImageExtensions = []string{".png", ".tga", ".dds", ".bmp", ".jpg", ".jpeg"}
+
+type PageReconciliation struct {
+	Missing  []string          // Pages listed in the descriptor but not found on disk
+	Orphaned []string          // Image files not referenced by any page, relative to the directory
+	Fixed    map[string]string // Page names that were rewritten, old name to new name
+}
+
+func (r *PageReconciliation) Clean() bool {
+	return len(r.Missing) == 0 && len(r.Orphaned) == 0
+}
+
+func (r *PageReconciliation) fixPage(f *Font, i int, name string) {
+	if r.Fixed == nil {
+		r.Fixed = make(map[string]string)
+	}
+	r.Fixed[f.Pages[i]] = name
+	f.SetPage(i, name)
+}
+
+func isPageImage(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, e := range PageImageExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// Page names are written by windows tools, so both separators are accepted
+func normalizePageName(name string) string {
+	return path.Clean(strings.ReplaceAll(name, "\\", "/"))
+}
+
+// ReconcilePages compares Pages against the image files in dir. When fix is
+// set, pages found on disk under windows path separators, and missing pages
+// that differ from an orphaned file only by letter case, are rewritten to the
+// name found on disk.
+func (f *Font) ReconcilePages(dir string, fix bool) (*PageReconciliation, error) {
+	r := &PageReconciliation{}
+
+	// Scan every directory that a page refers to, dir itself is always included
+	dirs := map[string]bool{".": true}
+	for _, p := range f.Pages {
+		dirs[path.Dir(normalizePageName(p))] = true
+	}
+
+	onDisk := make(map[string]bool)
+	for d := range dirs {
+		entries, err := os.ReadDir(filepath.Join(dir, filepath.FromSlash(d)))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("Error reading page directory %v: %v", d, err)
+		}
+		for _, e := range entries {
+			if !e.IsDir() && isPageImage(e.Name()) {
+				onDisk[path.Join(d, e.Name())] = true
+			}
+		}
+	}
+
+	referenced := make(map[string]bool)
+	for _, p := range f.Pages {
+		name := normalizePageName(p)
+		if onDisk[name] {
+			referenced[name] = true
+		}
+	}
+
+	var orphaned []string
+	for name := range onDisk {
+		if !referenced[name] {
+			orphaned = append(orphaned, name)
+		}
+	}
+	sort.Strings(orphaned)
+
+	for i, p := range f.Pages {
+		name := normalizePageName(p)
+		if onDisk[name] {
+			if fix && name != p {
+				r.fixPage(f, i, name)
+			}
+			continue
+		}
+
+		if fix {
+			match := -1
+			for j, o := range orphaned {
+				if strings.EqualFold(o, name) {
+					match = j
+					break
+				}
+			}
+			if match != -1 {
+				r.fixPage(f, i, orphaned[match])
+				orphaned = append(orphaned[:match], orphaned[match+1:]...)
+				continue
+			}
+		}
+		r.Missing = append(r.Missing, p)
+	}
+	r.Orphaned = orphaned
+
+	return r, nil
+}
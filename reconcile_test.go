@@ -0,0 +1,50 @@
+package bmfont
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReconcilePages(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"font_0.png", "sub/Font_1.png", "extra.tga", "notes.txt"} {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pages := []string{"Font_0.PNG", `sub\Font_1.png`, "font_2.png"}
+
+	for _, tc := range []struct {
+		fix   bool
+		pages []string
+		want  PageReconciliation
+	}{
+		{false, pages, PageReconciliation{
+			Missing:  []string{"Font_0.PNG", "font_2.png"},
+			Orphaned: []string{"extra.tga", "font_0.png"},
+		}},
+		{true, []string{"font_0.png", "sub/Font_1.png", "font_2.png"}, PageReconciliation{
+			Missing:  []string{"font_2.png"},
+			Orphaned: []string{"extra.tga"},
+			Fixed:    map[string]string{"Font_0.PNG": "font_0.png", `sub\Font_1.png`: "sub/Font_1.png"},
+		}},
+	} {
+		f := &Font{Pages: append([]string(nil), pages...)}
+		r, err := f.ReconcilePages(dir, tc.fix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(*r, tc.want) {
+			t.Errorf("fix %v: %+v, want %+v", tc.fix, *r, tc.want)
+		}
+		if !reflect.DeepEqual(f.Pages, tc.pages) {
+			t.Errorf("fix %v: pages %q, want %q", tc.fix, f.Pages, tc.pages)
+		}
+	}
+}
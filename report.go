@@ -0,0 +1,162 @@
+package bmfont
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"image/png"
+	"io"
+)
+
+// Kerning matrix is replaced with a plain list above this many chars
+const REPORT_MAX_KERNING_MATRIX = 64
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"kerningAmount": func(kp KerningPair) int16 { return int16(kp.Amount) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+td, th { border: 1px solid #ccc; padding: 2px 6px; text-align: right; }
+.page { position: relative; display: inline-block; margin: 0 1em 1em 0; background: #888; }
+.page img { display: block; image-rendering: pixelated; }
+.glyph { position: absolute; box-sizing: border-box; border: 1px solid rgba(255, 0, 0, 0.5); }
+.glyph:hover { border-color: #ff0; background: rgba(255, 255, 0, 0.3); }
+.zero { color: #ccc; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+
+{{with .Font.Info}}
+<h2>Info</h2>
+<table>
+<tr><th>Font size</th><td>{{.FontSize}}</td></tr>
+<tr><th>Bit field</th><td>{{printf "%08b" .BitField}}</td></tr>
+<tr><th>Charset</th><td>{{.CharSet}}</td></tr>
+<tr><th>Stretch H</th><td>{{.StretchH}}</td></tr>
+<tr><th>AA</th><td>{{.Aa}}</td></tr>
+<tr><th>Padding</th><td>{{.PaddingUp}}, {{.PaddingRight}}, {{.PaddingDown}}, {{.PaddingLeft}}</td></tr>
+<tr><th>Spacing</th><td>{{.SpacingHoriz}}, {{.SpacingVert}}</td></tr>
+<tr><th>Outline</th><td>{{.Outline}}</td></tr>
+</table>
+{{end}}
+
+{{with .Font.Common}}
+<h2>Common</h2>
+<table>
+<tr><th>Line height</th><td>{{.LineHeight}}</td></tr>
+<tr><th>Base</th><td>{{.Base}}</td></tr>
+<tr><th>Scale</th><td>{{.ScaleW}} x {{.ScaleH}}</td></tr>
+<tr><th>Pages</th><td>{{.Pages}}</td></tr>
+<tr><th>Bit field</th><td>{{printf "%08b" .BitField}}</td></tr>
+<tr><th>Channels (a, r, g, b)</th><td>{{.AlphaChnl}}, {{.RedChnl}}, {{.GreenChnl}}, {{.BlueChnl}}</td></tr>
+</table>
+{{end}}
+
+<h2>Pages</h2>
+{{range .Pages}}
+<div class="page">
+{{if .Image}}<img src="{{.Image}}" alt="{{.Name}}">{{else}}<p>{{.Name}} (not loaded)</p>{{end}}
+{{range .Glyphs}}<div class="glyph" style="left: {{.X}}px; top: {{.Y}}px; width: {{.Width}}px; height: {{.Height}}px" title="{{.Title}}"></div>{{end}}
+</div>
+{{end}}
+
+<h2>Chars</h2>
+<table>
+<tr><th>Id</th><th>Char</th><th>X</th><th>Y</th><th>Width</th><th>Height</th><th>Xoffset</th><th>Yoffset</th><th>Xadvance</th><th>Page</th><th>Chnl</th></tr>
+{{range .Font.Chars}}<tr><td>{{.Id}}</td><td>{{printf "%q" .Id}}</td><td>{{.X}}</td><td>{{.Y}}</td><td>{{.Width}}</td><td>{{.Height}}</td><td>{{.Xoffset}}</td><td>{{.Yoffset}}</td><td>{{.Xadvance}}</td><td>{{.Page}}</td><td>{{.Chnl}}</td></tr>
+{{end}}
+</table>
+
+<h2>Kerning</h2>
+{{if .Matrix}}
+<table>
+<tr><th></th>{{range .MatrixIds}}<th>{{printf "%c" .}}</th>{{end}}</tr>
+{{range $i, $row := .Matrix}}<tr><th>{{printf "%c" (index $.MatrixIds $i)}}</th>{{range $row}}<td{{if not .}} class="zero"{{end}}>{{.}}</td>{{end}}</tr>
+{{end}}
+</table>
+{{else}}
+<table>
+<tr><th>First</th><th>Second</th><th>Amount</th></tr>
+{{range .Font.KerningPairs}}<tr><td>{{printf "%q" .First}}</td><td>{{printf "%q" .Second}}</td><td>{{printf "%d" (kerningAmount .)}}</td></tr>
+{{end}}
+</table>
+{{end}}
+</body>
+</html>
+`))
+
+type reportGlyph struct {
+	X, Y, Width, Height int
+	Title               string
+}
+
+type reportPage struct {
+	Name   string
+	Image  template.URL
+	Glyphs []reportGlyph
+}
+
+type reportData struct {
+	Title     string
+	Font      *Font
+	Pages     []reportPage
+	MatrixIds []uint32
+	Matrix    [][]int16
+}
+
+// WriteHTMLReport writes a standalone HTML page with the atlas pages and
+// hoverable glyph boxes, metrics tables and the kerning matrix, for sharing
+// with people who do not have any font tools installed. pages may be nil.
+func (f *Font) WriteHTMLReport(w io.Writer, pages PageImages) error {
+	data := reportData{Font: f, Title: "BMFont report"}
+	if f.Info != nil && f.Info.FontName != "" {
+		data.Title = f.Info.FontName
+	}
+
+	pagesCnt := max(len(f.Pages), len(pages))
+	for i := 0; i < pagesCnt; i++ {
+		page := reportPage{Name: fmt.Sprintf("page %v", i)}
+		if i < len(f.Pages) {
+			page.Name = f.Pages[i]
+		}
+		if i < len(pages) && pages[i] != nil {
+			var buf bytes.Buffer
+			if err := png.Encode(&buf, pages[i]); err != nil {
+				return fmt.Errorf("Error encoding page %v: %v", i, err)
+			}
+			page.Image = template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()))
+		}
+		for _, c := range f.Chars {
+			if int(c.Page) == i && c.Width != 0 && c.Height != 0 {
+				page.Glyphs = append(page.Glyphs, reportGlyph{
+					X: int(c.X), Y: int(c.Y), Width: int(c.Width), Height: int(c.Height),
+					Title: fmt.Sprintf("%q id %v, offset %v,%v, advance %v, chnl %v",
+						rune(c.Id), c.Id, c.Xoffset, c.Yoffset, c.Xadvance, c.Chnl),
+				})
+			}
+		}
+		data.Pages = append(data.Pages, page)
+	}
+
+	ids, index := f.kerningIds()
+	if len(ids) != 0 && len(ids) <= REPORT_MAX_KERNING_MATRIX {
+		data.MatrixIds = ids
+
+		data.Matrix = make([][]int16, len(data.MatrixIds))
+		for i := range data.Matrix {
+			data.Matrix[i] = make([]int16, len(data.MatrixIds))
+		}
+		for _, kp := range f.KerningPairs {
+			data.Matrix[index[kp.First]][index[kp.Second]] = int16(kp.Amount)
+		}
+	}
+
+	return reportTemplate.Execute(w, &data)
+}
@@ -0,0 +1,181 @@
+package bmfont
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// RuntimeGlyph is glyph metrics as dumped from the memory of a running game
+type RuntimeGlyph struct {
+	Id      uint32
+	X       int
+	Y       int
+	Width   int
+	Height  int
+	Advance int
+}
+
+// Column or key names accepted in dumps, by the name they map to
+var runtimeDumpNames = map[string]string{
+	"id":       "id",
+	"x":        "x",
+	"y":        "y",
+	"w":        "w",
+	"width":    "w",
+	"h":        "h",
+	"height":   "h",
+	"advance":  "advance",
+	"xadvance": "advance",
+}
+
+var runtimeDumpFields = []string{"id", "x", "y", "w", "h", "advance"}
+
+func runtimeGlyphFromValues(values map[string]float64) (RuntimeGlyph, error) {
+	for _, name := range runtimeDumpFields {
+		if _, ok := values[name]; !ok {
+			return RuntimeGlyph{}, fmt.Errorf("Missing %v", name)
+		}
+	}
+	get := func(name string) int { return int(math.Round(values[name])) }
+	return RuntimeGlyph{
+		Id:      uint32(get("id")),
+		X:       get("x"),
+		Y:       get("y"),
+		Width:   get("w"),
+		Height:  get("h"),
+		Advance: get("advance"),
+	}, nil
+}
+
+// ReadRuntimeDump reads glyph metrics dumped as either a JSON array of
+// objects or CSV with a header line. Both use the names id, x, y, w, h and
+// advance; width, height and xadvance are accepted too. Values may be
+// floats, they are rounded to the nearest integer. Either may be UTF-8 or
+// UTF-16, as text descriptors.
+func ReadRuntimeDump(r io.Reader) ([]RuntimeGlyph, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	// Decoded before sniffing, the first char of a UTF-16 dump is two bytes
+	b, err = textDescriptor(b)
+	if err != nil {
+		return nil, fmt.Errorf("Error decoding dump text: %v", err)
+	}
+	if head := bytes.TrimLeft(b, " \t\r\n"); len(head) != 0 && head[0] == '[' {
+		return readRuntimeDumpJSON(b)
+	}
+	return readRuntimeDumpCSV(bytes.NewReader(b))
+}
+
+func readRuntimeDumpJSON(b []byte) ([]RuntimeGlyph, error) {
+	// Other keys may hold anything, e.g. the char as a string
+	var objects []map[string]json.RawMessage
+	if err := json.Unmarshal(b, &objects); err != nil {
+		return nil, fmt.Errorf("Error parsing json dump: %v", err)
+	}
+
+	glyphs := make([]RuntimeGlyph, 0, len(objects))
+	for i, object := range objects {
+		values := make(map[string]float64, len(object))
+		for key, raw := range object {
+			name, ok := runtimeDumpNames[strings.ToLower(key)]
+			if !ok {
+				continue
+			}
+			var v float64
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return nil, fmt.Errorf("Glyph %v %v: %v", i, key, err)
+			}
+			values[name] = v
+		}
+		g, err := runtimeGlyphFromValues(values)
+		if err != nil {
+			return nil, fmt.Errorf("Glyph %v: %v", i, err)
+		}
+		glyphs = append(glyphs, g)
+	}
+	return glyphs, nil
+}
+
+func readRuntimeDumpCSV(r io.Reader) ([]RuntimeGlyph, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("Error reading csv header: %v", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		if name, ok := runtimeDumpNames[strings.ToLower(strings.TrimSpace(name))]; ok {
+			columns[name] = i
+		}
+	}
+
+	var glyphs []RuntimeGlyph
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return glyphs, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("Error reading csv line %v: %v", line, err)
+		}
+
+		values := make(map[string]float64, len(columns))
+		for name, i := range columns {
+			if i >= len(record) {
+				continue
+			}
+			v, err := strconv.ParseFloat(strings.TrimSpace(record[i]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("Line %v column %v: %v", line, name, err)
+			}
+			values[name] = v
+		}
+		g, err := runtimeGlyphFromValues(values)
+		if err != nil {
+			return nil, fmt.Errorf("Line %v: %v", line, err)
+		}
+		glyphs = append(glyphs, g)
+	}
+}
+
+// CompareRuntimeDump reports every glyph of a runtime dump that the font
+// lacks or that differs in position, size or advance. Chars only in the font
+// are not reported, games rarely dump glyphs they never drew.
+func (f *Font) CompareRuntimeDump(glyphs []RuntimeGlyph) []Issue {
+	index := f.charIndexes()
+
+	var issues []Issue
+	for _, g := range glyphs {
+		i, ok := index[g.Id]
+		if !ok {
+			issues = append(issues, newIssue(nil, "char %v (%q) in runtime dump but not in font", g.Id, rune(g.Id)))
+			continue
+		}
+		c := &f.Chars[i]
+
+		for _, field := range []struct {
+			name          string
+			font, runtime int
+		}{
+			{"x", int(c.X), g.X},
+			{"y", int(c.Y), g.Y},
+			{"width", int(c.Width), g.Width},
+			{"height", int(c.Height), g.Height},
+			{"xadvance", int(c.Xadvance), g.Advance},
+		} {
+			if field.font != field.runtime {
+				issues = append(issues, newIssue(c, "%v is %v, runtime uses %v", field.name, field.font, field.runtime))
+			}
+		}
+	}
+	return issues
+}
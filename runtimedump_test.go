@@ -0,0 +1,60 @@
+package bmfont
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestReadRuntimeDumpEncodings(t *testing.T) {
+	want := []RuntimeGlyph{{Id: 'A', X: 1, Y: 2, Width: 6, Height: 8, Advance: 7}}
+	for name, dump := range map[string]string{
+		"json": `[{"id": 65, "x": 1, "y": 2, "w": 6, "h": 8, "advance": 6.6}]`,
+		"csv":  "id,x,y,width,height,xadvance\n65,1,2,6,8,6.6\n",
+	} {
+		for encName, enc := range map[string]interface {
+			Bytes([]byte) ([]byte, error)
+		}{
+			"utf8 bom":    unicode.UTF8BOM.NewEncoder(),
+			"utf16le bom": unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder(),
+			"utf16le":     unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewEncoder(),
+			"utf16be":     unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM).NewEncoder(),
+		} {
+			b, err := enc.Bytes([]byte(dump))
+			if err != nil {
+				t.Fatal(err)
+			}
+			glyphs, err := ReadRuntimeDump(bytes.NewReader(b))
+			if err != nil {
+				t.Errorf("%v %v: %v", name, encName, err)
+			} else if !reflect.DeepEqual(glyphs, want) {
+				t.Errorf("%v %v: glyphs %+v, want %+v", name, encName, glyphs, want)
+			}
+		}
+	}
+}
+
+func TestCompareRuntimeDumpMissingChar(t *testing.T) {
+	f := &Font{Chars: []Char{{Id: 'A', X: 1, Y: 2, Width: 6, Height: 8, Xadvance: 7}}}
+	issues := f.CompareRuntimeDump([]RuntimeGlyph{
+		{Id: 'A', X: 1, Y: 2, Width: 6, Height: 8, Advance: 7},
+		{Id: 'B', Width: 6, Height: 8, Advance: 7},
+	})
+	if len(issues) != 1 || issues[0].Char != nil || !strings.Contains(issues[0].String(), "66 ('B')") {
+		t.Errorf("issues %+v", issues)
+	}
+}
+
+func TestCompareRuntimeDumpDuplicateIds(t *testing.T) {
+	f := &Font{Chars: []Char{
+		{Id: 'A', Width: 6, Height: 8, Xadvance: 7},
+		{Id: 'A', Width: 9, Height: 9, Xadvance: 9},
+	}}
+	issues := f.CompareRuntimeDump([]RuntimeGlyph{{Id: 'A', Width: 6, Height: 8, Advance: 7}})
+	if len(issues) != 0 {
+		t.Errorf("compared against the second char: %v", issues)
+	}
+}
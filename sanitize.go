@@ -0,0 +1,132 @@
+package bmfont
+
+import (
+	"math"
+)
+
+// Bounds of Sanitize relative to the reference size of the font, the larger
+// of |Info.FontSize| and Common.LineHeight
+const (
+	DEFAULT_SANITIZE_OFFSET_FACTOR  = 2
+	DEFAULT_SANITIZE_ADVANCE_FACTOR = 4
+)
+
+type SanitizeOptions struct {
+	Clamp         bool    // Clamp out of range values instead of only reporting them
+	OffsetFactor  float64 // Xoffset and Yoffset must be within ±OffsetFactor * size
+	AdvanceFactor float64 // Xadvance must be within 0..AdvanceFactor * size
+}
+
+func (f *Font) referenceSize() int {
+	size := 0
+	if f.Info != nil {
+		size = int(f.Info.FontSize)
+		if size < 0 {
+			size = -size
+		}
+	}
+	if f.Common != nil {
+		size = max(size, int(f.Common.LineHeight))
+	}
+	return size
+}
+
+// Sanitize looks for offsets and advances that are far out of proportion to
+// the font size, which exporter bugs produce, so a single broken char cannot
+// wreck a whole layout. Fonts without any size information are left alone.
+func (f *Font) Sanitize(opts *SanitizeOptions) []Issue {
+	if opts == nil {
+		opts = &SanitizeOptions{}
+	}
+	offsetFactor, advanceFactor := opts.OffsetFactor, opts.AdvanceFactor
+	if offsetFactor == 0 {
+		offsetFactor = DEFAULT_SANITIZE_OFFSET_FACTOR
+	}
+	if advanceFactor == 0 {
+		advanceFactor = DEFAULT_SANITIZE_ADVANCE_FACTOR
+	}
+
+	size := f.referenceSize()
+	if size == 0 {
+		return nil
+	}
+	bound := func(factor float64) int16 {
+		return int16(math.Min(math.MaxInt16, math.Round(factor*float64(size))))
+	}
+	maxOffset, maxAdvance := bound(offsetFactor), bound(advanceFactor)
+
+	var issues []Issue
+	// c is the char in the font for the issue, v the field in a copy of it
+	check := func(c *Char, name string, v *int16, lo, hi int16) {
+		if *v >= lo && *v <= hi {
+			return
+		}
+		clamped := min(max(*v, lo), hi)
+		if opts.Clamp {
+			issues = append(issues, newIssue(c, "%v %v out of range %v..%v, clamped to %v", name, *v, lo, hi, clamped))
+			*v = clamped
+		} else {
+			issues = append(issues, newIssue(c, "%v %v out of range %v..%v", name, *v, lo, hi))
+		}
+	}
+
+	for i := range f.Chars {
+		c := f.Chars[i]
+		check(&f.Chars[i], "xoffset", &c.Xoffset, -maxOffset, maxOffset)
+		check(&f.Chars[i], "yoffset", &c.Yoffset, -maxOffset, maxOffset)
+		check(&f.Chars[i], "xadvance", &c.Xadvance, 0, maxAdvance)
+		f.setCharAt(i, c)
+	}
+	return issues
+}
+
+type WhitespaceAdvance int
+
+// Where the advance of synthesized whitespace comes from
+const (
+	WHITESPACE_ADVANCE_FONT_SIZE   WhitespaceAdvance = iota // A quarter of the reference size
+	WHITESPACE_ADVANCE_DIGIT_WIDTH                          // Average advance of the digits 0-9
+)
+
+type WhitespaceOptions struct {
+	From    WhitespaceAdvance
+	Advance int16 // Explicit advance, overrides From when not 0
+}
+
+func (f *Font) whitespaceAdvance(opts *WhitespaceOptions) int16 {
+	if opts.Advance != 0 {
+		return opts.Advance
+	}
+
+	if opts.From == WHITESPACE_ADVANCE_DIGIT_WIDTH {
+		if digits, ok := f.averageAdvance('0', '9'); ok {
+			return int16(math.Round(digits))
+		}
+	}
+	return int16((f.referenceSize() + 2) / 4)
+}
+
+// SynthesizeWhitespace adds empty chars for space and no-break space when the
+// font lacks them. No-break space gets the advance of the space. Returns the
+// ids of the added chars.
+func (f *Font) SynthesizeWhitespace(opts *WhitespaceOptions) []uint32 {
+	if opts == nil {
+		opts = &WhitespaceOptions{}
+	}
+
+	var added []uint32
+	var advance int16
+	if space := f.FindChar(' '); space != nil {
+		advance = space.Xadvance
+	} else {
+		advance = f.whitespaceAdvance(opts)
+		f.AddChar(Char{Id: ' ', Xadvance: advance, Chnl: CHNL_ALL})
+		added = append(added, ' ')
+	}
+
+	if f.FindChar('\u00a0') == nil {
+		f.AddChar(Char{Id: '\u00a0', Xadvance: advance, Chnl: CHNL_ALL})
+		added = append(added, '\u00a0')
+	}
+	return added
+}
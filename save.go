@@ -0,0 +1,224 @@
+package bmfont
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type Format int
+
+const (
+	FORMAT_BINARY Format = iota
+	FORMAT_JSON
+)
+
+func (format Format) String() string {
+	switch format {
+	case FORMAT_BINARY:
+		return "binary"
+	case FORMAT_JSON:
+		return "json"
+	default:
+		return fmt.Sprintf("Format(%d)", int(format))
+	}
+}
+
+func DetectFormat(b []byte) (Format, error) {
+	if bytes.HasPrefix(b, []byte("BMF")) {
+		return FORMAT_BINARY, nil
+	}
+	if text, err := textDescriptor(b); err == nil {
+		if trimmed := bytes.TrimLeft(text, " \t\r\n"); len(trimmed) != 0 && trimmed[0] == '{' {
+			return FORMAT_JSON, nil
+		}
+	}
+	return 0, fmt.Errorf("Unknown font descriptor format")
+}
+
+type WriteOptions struct {
+	Options
+
+	// When set, page names are regenerated from these options instead of
+	// writing the names the font was parsed with. The font is not modified.
+	PageNames *PageNameOptions
+
+	// Size reductions for shipping builds, see also EncodeForShipping
+	StripFontName    bool  // Write an empty font name
+	KerningThreshold int16 // Drop kerning pairs with an absolute amount below this
+	CompactJSON      bool  // No indentation and no "char" helper field
+}
+
+// Returns a shallow copy of the font with write options applied, or the font
+// itself when nothing changes
+func (f *Font) applyWriteOptions(opts *WriteOptions) *Font {
+	if opts.PageNames == nil && !opts.StripFontName && opts.KerningThreshold <= 0 {
+		return f
+	}
+	out := *f
+
+	if opts.PageNames != nil {
+		out.Pages = make([]string, len(f.Pages))
+		pageNames := *opts.PageNames
+		if pageNames.Name == "" && f.Info != nil {
+			pageNames.Name = f.Info.FontName
+		}
+		for i := range out.Pages {
+			out.Pages[i] = pageNames.PageName(i)
+		}
+	}
+
+	if opts.StripFontName && f.Info != nil {
+		info := *f.Info
+		info.FontName = ""
+		out.Info = &info
+	}
+
+	if opts.KerningThreshold > 0 {
+		out.KerningPairs = nil
+		for _, kp := range f.KerningPairs {
+			if abs(int(int16(kp.Amount))) >= int(opts.KerningThreshold) {
+				out.KerningPairs = append(out.KerningPairs, kp)
+			}
+		}
+	}
+	return &out
+}
+
+func (f *Font) Encode(format Format) ([]byte, error) {
+	return f.EncodeWithOptions(format, nil)
+}
+
+func (f *Font) EncodeWithOptions(format Format, opts *WriteOptions) ([]byte, error) {
+	if opts == nil {
+		opts = &WriteOptions{}
+	}
+
+	f = f.applyWriteOptions(opts)
+
+	switch format {
+	case FORMAT_BINARY:
+		return f.ToBufferWithOptions(&opts.Options)
+	case FORMAT_JSON:
+		if opts.CompactJSON {
+			return json.Marshal(f.toJSON(true))
+		}
+		return json.MarshalIndent(f, "", "  ")
+	default:
+		return nil, fmt.Errorf("Unsupported format %v", format)
+	}
+}
+
+func (f *Font) Decode(b []byte, format Format) error {
+	return f.DecodeWithOptions(b, format, nil)
+}
+
+func (f *Font) DecodeWithOptions(b []byte, format Format, opts *Options) error {
+	switch format {
+	case FORMAT_BINARY:
+		return f.FromBufferWithOptions(b, opts)
+	case FORMAT_JSON:
+		return f.FromJSONWithOptions(b, opts)
+	default:
+		return fmt.Errorf("Unsupported format %v", format)
+	}
+}
+
+// decode is DecodeWithOptions without counting towards the parse metrics,
+// for files the package reads on its own behalf
+func (f *Font) decode(b []byte, format Format, opts *Options) error {
+	switch format {
+	case FORMAT_BINARY:
+		return f.fromBuffer(b, opts)
+	case FORMAT_JSON:
+		return f.fromJSON(b, opts)
+	default:
+		return fmt.Errorf("Unsupported format %v", format)
+	}
+}
+
+type SaveOptions struct {
+	WriteOptions
+	Backup bool // Keep the previous version of the file as path + ".bak"
+	Force  bool // Overwrite the existing file even if it does not parse
+}
+
+func (f *Font) SaveFileAtomic(path string, format Format) error {
+	return f.SaveFileAtomicWithOptions(path, format, nil)
+}
+
+// SaveFileAtomicWithOptions writes the font to a temporary file next to path
+// and renames it over path. An existing file that fails to parse is most
+// likely being written by someone else, so it is left untouched unless
+// opts.Force is set.
+func (f *Font) SaveFileAtomicWithOptions(path string, format Format, opts *SaveOptions) error {
+	if opts == nil {
+		opts = &SaveOptions{}
+	}
+
+	writeOpts := opts.WriteOptions
+	if writeOpts.PageNames != nil && writeOpts.PageNames.BaseName == "" {
+		pageNames := *writeOpts.PageNames
+		pageNames.BaseName = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		writeOpts.PageNames = &pageNames
+	}
+
+	b, err := f.EncodeWithOptions(format, &writeOpts)
+	if err != nil {
+		return fmt.Errorf("Error encoding font: %v", err)
+	}
+
+	mode := os.FileMode(0644)
+	previous, err := os.ReadFile(path)
+	if err == nil {
+		if !opts.Force {
+			existingFormat, err := DetectFormat(previous)
+			if err == nil {
+				err = NewFont().decode(previous, existingFormat, nil)
+			}
+			if err != nil {
+				return fmt.Errorf("Refusing to overwrite %v, existing file does not parse: %v", path, err)
+			}
+		}
+		if fi, err := os.Stat(path); err == nil {
+			mode = fi.Mode().Perm()
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("Error reading existing file %v: %v", path, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("Error creating temporary file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("Error writing temporary file: %v", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("Error syncing temporary file: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("Error closing temporary file: %v", err)
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return fmt.Errorf("Error setting temporary file mode: %v", err)
+	}
+
+	if opts.Backup && previous != nil {
+		if err := os.WriteFile(path+".bak", previous, mode); err != nil {
+			return fmt.Errorf("Error writing backup: %v", err)
+		}
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("Error replacing %v: %v", path, err)
+	}
+	return nil
+}
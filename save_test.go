@@ -0,0 +1,96 @@
+package bmfont
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func saveFont() *Font {
+	return &Font{
+		Info:   &Info{FontSize: 10, FontName: "Save"},
+		Common: &Common{LineHeight: 10, Base: 8, Pages: 1},
+		Pages:  []string{"save_0.png"},
+		Chars:  []Char{{Id: 'A', Width: 6, Height: 8, Xadvance: 7}},
+	}
+}
+
+func TestSaveFileAtomicRefusesUnparsable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "font.fnt")
+	garbage := []byte("BMF\x03\x01\xff")
+	if err := os.WriteFile(path, garbage, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := saveFont().SaveFileAtomic(path, FORMAT_BINARY); err == nil {
+		t.Fatal("overwrote a file that does not parse")
+	}
+	if b, _ := os.ReadFile(path); !bytes.Equal(b, garbage) {
+		t.Errorf("file changed to %q", b)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(filepath.Dir(path), ".*tmp*")); len(matches) != 0 {
+		t.Errorf("temporary files left behind: %v", matches)
+	}
+
+	if err := saveFont().SaveFileAtomicWithOptions(path, FORMAT_BINARY, &SaveOptions{Force: true}); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(path); bytes.Equal(b, garbage) {
+		t.Errorf("forced save kept the old file")
+	}
+}
+
+func TestSaveFileAtomicBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "font.json")
+	if err := saveFont().SaveFileAtomic(path, FORMAT_JSON); err != nil {
+		t.Fatal(err)
+	}
+	previous, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := saveFont()
+	f.Chars[0].Xadvance = 8
+	if err := f.SaveFileAtomicWithOptions(path, FORMAT_JSON, &SaveOptions{Backup: true}); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(path + ".bak"); !bytes.Equal(b, previous) {
+		t.Errorf("backup holds %q, want %q", b, previous)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, err := NewFontFromJSON(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Chars[0].Xadvance != 8 {
+		t.Errorf("saved xadvance %v", saved.Chars[0].Xadvance)
+	}
+}
+
+func TestSaveFileAtomicKeepsMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "font.fnt")
+	if err := saveFont().SaveFileAtomic(path, FORMAT_BINARY); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := saveFont().SaveFileAtomicWithOptions(path, FORMAT_BINARY, &SaveOptions{Backup: true}); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{path, path + ".bak"} {
+		fi, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Mode().Perm() != 0600 {
+			t.Errorf("%v has mode %v, want 0600", filepath.Base(name), fi.Mode().Perm())
+		}
+	}
+}
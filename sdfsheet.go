@@ -0,0 +1,129 @@
+package bmfont
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+)
+
+// Scales rendered by the contact sheet when none are given
+var DEFAULT_CONTACT_SHEET_SCALES = []float64{0.25, 0.5, 1, 2, 4}
+
+const contactSheetMargin = 4
+
+// Bilinear sample of the distance field inside the glyph rect, 0..1
+func sampleSDF(img image.Image, c *Char, chnl uint8, fieldType string, sx, sy float64) float64 {
+	x0, y0 := img.Bounds().Min.X+int(c.X), img.Bounds().Min.Y+int(c.Y)
+	w, h := int(c.Width), int(c.Height)
+
+	sx = math.Max(0, math.Min(sx-0.5, float64(w-1)))
+	sy = math.Max(0, math.Min(sy-0.5, float64(h-1)))
+	ix, iy := int(sx), int(sy)
+	fx, fy := sx-float64(ix), sy-float64(iy)
+	ix1, iy1 := min(ix+1, w-1), min(iy+1, h-1)
+
+	at := func(x, y int) float64 {
+		return float64(sdfValue(img, x0+x, y0+y, chnl, fieldType)) / 255
+	}
+	top := at(ix, iy)*(1-fx) + at(ix1, iy)*fx
+	bottom := at(ix, iy1)*(1-fx) + at(ix1, iy1)*fx
+	return top*(1-fy) + bottom*fy
+}
+
+func smoothstep(edge0, edge1, x float64) float64 {
+	t := math.Max(0, math.Min((x-edge0)/(edge1-edge0), 1))
+	return t * t * (3 - 2*t)
+}
+
+func (f *Font) drawSDFLine(dst *image.RGBA, pages PageImages, text string, scale float64, originX, originY int) {
+	fieldType := f.DistanceField.FieldType
+	// Half a destination pixel of antialiasing, in distance field units
+	smoothing := math.Min(0.5, 0.5/(float64(f.DistanceField.DistanceRange)*scale))
+
+	pen, prev := 0, uint32(0)
+	for _, r := range text {
+		id, visible := lineRune(r, f.hasChar)
+		if !visible {
+			continue
+		}
+		c := f.FindChar(id)
+		if c == nil {
+			prev = id
+			continue
+		}
+		pen += int(f.KerningAmount(prev, c.Id))
+		prev = c.Id
+
+		if c.Width != 0 && c.Height != 0 && int(c.Page) < len(pages) && pages[c.Page] != nil {
+			gx := float64(originX) + float64(pen+int(c.Xoffset))*scale
+			gy := float64(originY) + float64(c.Yoffset)*scale
+			gw, gh := float64(c.Width)*scale, float64(c.Height)*scale
+			chnl := f.CharChnl(c)
+
+			for y := int(math.Floor(gy)); y < int(math.Ceil(gy+gh)); y++ {
+				for x := int(math.Floor(gx)); x < int(math.Ceil(gx+gw)); x++ {
+					if !(image.Point{x, y}).In(dst.Rect) {
+						continue
+					}
+					d := sampleSDF(pages[c.Page], c, chnl, fieldType, (float64(x)+0.5-gx)/scale, (float64(y)+0.5-gy)/scale)
+					coverage := smoothstep(0.5-smoothing, 0.5+smoothing, d)
+					if coverage <= 0 {
+						continue
+					}
+					old := dst.RGBAAt(x, y)
+					v := uint8(math.Round(float64(old.R) * (1 - coverage)))
+					dst.SetRGBA(x, y, color.RGBA{v, v, v, 255})
+				}
+			}
+		}
+		pen += int(c.Xadvance)
+	}
+}
+
+// RenderSDFContactSheet draws text once per scale, one row per scale from top
+// to bottom, black on white. Used to review distance range and smoothing of
+// distance field fonts before they ship.
+func (f *Font) RenderSDFContactSheet(pages PageImages, text string, scales []float64) (*image.RGBA, error) {
+	if f.DistanceField == nil || f.DistanceField.DistanceRange <= 0 {
+		return nil, fmt.Errorf("Font has no distance field metadata")
+	}
+	if f.Common == nil {
+		return nil, fmt.Errorf("Font has no common block")
+	}
+	if len(scales) == 0 {
+		scales = DEFAULT_CONTACT_SHEET_SCALES
+	}
+
+	advance := f.Width(text)
+	width, height := 0, contactSheetMargin
+	for _, scale := range scales {
+		if scale <= 0 {
+			return nil, fmt.Errorf("Invalid scale %v", scale)
+		}
+		width = max(width, int(math.Ceil(float64(advance)*scale))+2*contactSheetMargin)
+		height += int(math.Ceil(float64(f.Common.LineHeight)*scale)) + contactSheetMargin
+	}
+
+	sheet := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := range sheet.Pix {
+		sheet.Pix[i] = 255
+	}
+
+	y := contactSheetMargin
+	for _, scale := range scales {
+		f.drawSDFLine(sheet, pages, text, scale, contactSheetMargin, y)
+		y += int(math.Ceil(float64(f.Common.LineHeight)*scale)) + contactSheetMargin
+	}
+	return sheet, nil
+}
+
+func (f *Font) WriteSDFContactSheet(w io.Writer, pages PageImages, text string, scales []float64) error {
+	sheet, err := f.RenderSDFContactSheet(pages, text, scales)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, sheet)
+}
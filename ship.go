@@ -0,0 +1,72 @@
+package bmfont
+
+import (
+	"fmt"
+)
+
+type ShipOptions struct {
+	BaselineFormat   Format // Format shipped so far, the report compares against it
+	StripFontName    bool
+	KerningThreshold int16 // Drop kerning pairs with an absolute amount below this
+}
+
+type ShipReport struct {
+	Format              Format // Format that produced the smallest output
+	BaselineSize        int    // Size of the font encoded as is in BaselineFormat
+	Size                int
+	DroppedKerningPairs int
+}
+
+func (r *ShipReport) Saved() int {
+	return r.BaselineSize - r.Size
+}
+
+func (r *ShipReport) String() string {
+	return fmt.Sprintf("%v bytes as %v, %v bytes saved (%v kerning pairs dropped)",
+		r.Size, r.Format, r.Saved(), r.DroppedKerningPairs)
+}
+
+// EncodeForShipping applies the size reductions in opts, encodes the font in
+// every format able to hold it (JSON in its compact form) and returns the
+// smallest output together with a report of the bytes saved
+func (f *Font) EncodeForShipping(opts *ShipOptions) ([]byte, *ShipReport, error) {
+	if opts == nil {
+		opts = &ShipOptions{}
+	}
+
+	baseline, err := f.Encode(opts.BaselineFormat)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error encoding baseline: %v", err)
+	}
+
+	writeOpts := &WriteOptions{
+		StripFontName:    opts.StripFontName,
+		KerningThreshold: opts.KerningThreshold,
+		CompactJSON:      true,
+	}
+	report := &ShipReport{
+		BaselineSize:        len(baseline),
+		DroppedKerningPairs: len(f.KerningPairs) - len(f.applyWriteOptions(writeOpts).KerningPairs),
+	}
+
+	formats := []Format{FORMAT_BINARY, FORMAT_JSON}
+	if f.DistanceField != nil {
+		// Binary would silently lose the distance field metadata
+		formats = []Format{FORMAT_JSON}
+	}
+
+	var smallest []byte
+	for _, format := range formats {
+		b, err := f.EncodeWithOptions(format, writeOpts)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Error encoding %v: %v", format, err)
+		}
+		if smallest == nil || len(b) < len(smallest) {
+			smallest = b
+			report.Format = format
+		}
+	}
+
+	report.Size = len(smallest)
+	return smallest, report, nil
+}
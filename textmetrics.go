@@ -0,0 +1,112 @@
+package bmfont
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// TextMetrics is the part of a font needed to measure text, without any atlas
+// data. It is meant to be shipped to services that lay out text on the server.
+type TextMetrics struct {
+	LineHeight uint16           `json:"lineHeight"`
+	Base       uint16           `json:"base"`
+	Advances   map[uint32]int16 `json:"advances"`           // Xadvance by char id
+	Kernings   [][3]int64       `json:"kernings,omitempty"` // first, second, amount
+
+	// Kernings by pair, built by Font.TextMetrics and ReadTextMetrics
+	kerning map[[2]uint32]int64
+}
+
+// Like Font.KerningAmount, the first of duplicate pairs wins
+func (m *TextMetrics) kerningMap() map[[2]uint32]int64 {
+	kerning := make(map[[2]uint32]int64, len(m.Kernings))
+	for _, k := range m.Kernings {
+		pair := [2]uint32{uint32(k[0]), uint32(k[1])}
+		if _, ok := kerning[pair]; !ok {
+			kerning[pair] = k[2]
+		}
+	}
+	return kerning
+}
+
+func (f *Font) TextMetrics() *TextMetrics {
+	m := &TextMetrics{
+		Advances: make(map[uint32]int16, len(f.Chars)),
+	}
+	if f.Common != nil {
+		m.LineHeight = f.Common.LineHeight
+		m.Base = f.Common.Base
+	}
+	// Duplicates are dropped, Font.FindChar and Font.KerningAmount use the
+	// first match
+	for _, c := range f.Chars {
+		if _, ok := m.Advances[c.Id]; !ok {
+			m.Advances[c.Id] = c.Xadvance
+		}
+	}
+	seen := make(map[[2]uint32]bool, len(f.KerningPairs))
+	for _, kp := range f.KerningPairs {
+		pair := [2]uint32{kp.First, kp.Second}
+		if seen[pair] {
+			continue
+		}
+		seen[pair] = true
+		if kp.Amount != 0 {
+			m.Kernings = append(m.Kernings, [3]int64{int64(kp.First), int64(kp.Second), int64(int16(kp.Amount))})
+		}
+	}
+	// Stable output keeps sidecars diffable
+	sort.Slice(m.Kernings, func(i, j int) bool {
+		if m.Kernings[i][0] != m.Kernings[j][0] {
+			return m.Kernings[i][0] < m.Kernings[j][0]
+		}
+		return m.Kernings[i][1] < m.Kernings[j][1]
+	})
+	m.kerning = m.kerningMap()
+	return m
+}
+
+func (f *Font) WriteTextMetrics(w io.Writer) error {
+	return json.NewEncoder(w).Encode(f.TextMetrics())
+}
+
+func ReadTextMetrics(r io.Reader) (*TextMetrics, error) {
+	m := &TextMetrics{}
+	if err := json.NewDecoder(r).Decode(m); err != nil {
+		return nil, fmt.Errorf("Error parsing text metrics: %v", err)
+	}
+	m.kerning = m.kerningMap()
+	return m, nil
+}
+
+// Width returns the advance of a single line of text with the same integer
+// math as Font.Width, so a server measuring with the sidecar gets exactly the
+// value the client computed from the full font. Metrics built by hand
+// rather than by Font.TextMetrics or ReadTextMetrics index their kernings on
+// every call.
+func (m *TextMetrics) Width(text string) int {
+	kerning := m.kerning
+	if kerning == nil && len(m.Kernings) != 0 {
+		kerning = m.kerningMap()
+	}
+
+	hasChar := func(id uint32) bool {
+		_, ok := m.Advances[id]
+		return ok
+	}
+
+	width, prev := 0, uint32(0)
+	for _, r := range text {
+		id, visible := lineRune(r, hasChar)
+		if !visible {
+			continue
+		}
+		if advance, ok := m.Advances[id]; ok {
+			width += int(kerning[[2]uint32{prev, id}]) + int(advance)
+		}
+		prev = id
+	}
+	return width
+}
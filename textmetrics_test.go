@@ -0,0 +1,66 @@
+package bmfont
+
+import (
+	"bytes"
+	"testing"
+)
+
+func testMetricsFont() *Font {
+	return &Font{
+		Common: &Common{LineHeight: 20, Base: 16},
+		Chars: []Char{
+			{Id: 'A', Xadvance: 10},
+			{Id: 'V', Xadvance: 11},
+			{Id: 'W', Xadvance: 14},
+			{Id: 'A', Xadvance: 99}, // Duplicate, first one wins
+			{Id: ' ', Xadvance: 4},
+		},
+		KerningPairs: []KerningPair{
+			{First: 'A', Second: 'V', Amount: uint16(0xffff - 2)}, // -3
+			{First: 'A', Second: 'V', Amount: 0},                  // Duplicate, first one wins
+			{First: 'V', Second: 'A', Amount: 0},
+			{First: 'V', Second: 'A', Amount: uint16(0xffff)}, // Duplicate of a zero pair
+			{First: 'W', Second: 'A', Amount: 2},
+		},
+	}
+}
+
+func TestTextMetricsWidthMatchesFont(t *testing.T) {
+	f := testMetricsFont()
+
+	var buf bytes.Buffer
+	if err := f.WriteTextMetrics(&buf); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadTextMetrics(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handMade := &TextMetrics{Advances: read.Advances, Kernings: read.Kernings}
+
+	for _, tc := range []struct {
+		text  string
+		width int
+	}{
+		{"", 0},
+		{"A", 10},
+		{"AV", 18},
+		{"VA", 21},
+		{"WA", 26},
+		{"AVA", 28},
+		{"A V", 25},
+		{"A?V", 21},
+	} {
+		metrics := map[string]interface{ Width(string) int }{
+			"font":      f,
+			"metrics":   f.TextMetrics(),
+			"read":      read,
+			"hand made": handMade,
+		}
+		for name, m := range metrics {
+			if got := m.Width(tc.text); got != tc.width {
+				t.Errorf("%v: Width(%q) = %v, want %v", name, tc.text, got, tc.width)
+			}
+		}
+	}
+}